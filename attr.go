@@ -0,0 +1,89 @@
+package gonode
+
+// Assigns the given attribute (key and value) for this Node
+//
+// Attributes are key/value pairs kept alongside tags (i.e. "unit" = "celsius")
+func (n *Node) SetAttr(key, value string) {
+	if n.attrs == nil {
+		n.attrs = map[string]string{}
+	}
+	n.attrs[key] = value
+}
+
+// Obtains the value of the given attribute for this Node
+//
+// Returns false if this Node doesn't have the attribute
+func (n *Node) Attr(key string) (string, bool) {
+	value, ok := n.attrs[key]
+	return value, ok
+}
+
+// Checks if this Node has the given attribute with the given value
+func (n *Node) HasAttr(key, value string) bool {
+	v, ok := n.attrs[key]
+	return ok && v == value
+}
+
+// Removes the given attribute(s) from this Node
+func (n *Node) RmAttr(keys ...string) {
+	for _, key := range keys {
+		delete(n.attrs, key)
+	}
+}
+
+// Removes all attributes from this Node
+func (n *Node) RmAllAttrs() {
+	n.attrs = nil
+}
+
+// Obtains a copy of all attributes of this Node
+func (n *Node) Attrs() map[string]string {
+	atrs := make(map[string]string, len(n.attrs))
+	for k, v := range n.attrs {
+		atrs[k] = v
+	}
+	return atrs
+}
+
+// Returns the first child which has the given attribute with the given value
+//
+// Returns nil if no children match
+func (n *Node) ChildByAttr(key, value string) *Node {
+	for _, kid := range n.children {
+		if kid.HasAttr(key, value) {
+			return kid
+		}
+	}
+	return nil
+}
+
+// Returns the first child which has the given attribute with the given value
+//
+// # This Deep version will call it recursively on children too
+//
+// Returns nil if no children match
+func (n *Node) ChildByAttrDeep(key, value string) *Node {
+	for _, kid := range n.children {
+		if kid.HasAttr(key, value) {
+			return kid
+		} else if kid.Len() != 0 {
+			r := kid.ChildByAttrDeep(key, value)
+			if r != nil {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the index of the first child which has the given attribute with the given value
+//
+// Returns -1 if no children match
+func (n *Node) ChildIndexByAttr(key, value string) int {
+	for idx, kid := range n.children {
+		if kid.HasAttr(key, value) {
+			return idx
+		}
+	}
+	return -1
+}
@@ -0,0 +1,65 @@
+package gonode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestNodeAttrs(t *testing.T) {
+	n := gonode.NewNode()
+	a := n.NewChildWithDataAndTags(32, "freezing water")
+	a.SetAttr("unit", "celsius")
+	b := n.NewChildWithDataAndTags(212, "boiling water")
+	b.SetAttr("unit", "fahrenheit")
+	c := b.NewChildWithTags("kelvin")
+	c.SetAttr("unit", "kelvin")
+
+	if v, ok := a.Attr("unit"); !ok || v != "celsius" {
+		t.Errorf("Expected 'celsius' as 'unit', got '%s' (%v)", v, ok)
+	}
+	if _, ok := a.Attr("missing"); ok {
+		t.Errorf("Expected no 'missing' attribute")
+	}
+	if n.ChildByAttr("unit", "fahrenheit") != b {
+		t.Errorf("Expected to find 'boiling water' by attribute")
+	}
+	if n.ChildByAttr("unit", "kelvin") != nil {
+		t.Errorf("Expected nil, 'kelvin' is nested")
+	}
+	if n.ChildByAttrDeep("unit", "kelvin") != c {
+		t.Errorf("Expected to find 'kelvin' by deep attribute")
+	}
+	if n.ChildIndexByAttr("unit", "fahrenheit") != 1 {
+		t.Errorf("Expected 'boiling water' at index 1")
+	}
+
+	atrs := a.Attrs()
+	atrs["unit"] = "changed"
+	if v, _ := a.Attr("unit"); v != "celsius" {
+		t.Errorf("Expected Attrs to return a copy, got '%s'", v)
+	}
+
+	pay, err := json.Marshal(n)
+	if err != nil {
+		t.Fatalf("json.Marshal %v", err)
+	}
+	dummy := gonode.NewNode()
+	err = json.Unmarshal(pay, &dummy)
+	if err != nil {
+		t.Fatalf("json.Unmarshal %v", err)
+	}
+	if dummy.ChildByAttrDeep("unit", "kelvin") == nil || dummy.ChildByAttr("unit", "celsius") == nil {
+		t.Errorf("Expected attributes to survive json, got %s", pay)
+	}
+
+	a.RmAttr("unit")
+	if _, ok := a.Attr("unit"); ok {
+		t.Errorf("Expected 'unit' removed")
+	}
+	b.RmAllAttrs()
+	if len(b.Attrs()) != 0 {
+		t.Errorf("Expected no attributes, got %v", b.Attrs())
+	}
+}
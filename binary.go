@@ -0,0 +1,462 @@
+package gonode
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Node)(nil)
+	_ encoding.BinaryUnmarshaler = (*Node)(nil)
+)
+
+// The binary format
+//
+//	"GONB" version
+//	string table: count, then each string as length + bytes (tags, attribute keys and values)
+//	top Node
+//
+// Each Node is: tag count + string indexes, attribute count + key/value string indexes,
+// data (a kind byte followed by it's value) and child count followed by the children.
+// All counts, lengths and indexes are uvarints.
+const (
+	binaryMagic   = "GONB"
+	binaryVersion = 1
+	// Nodes nested deeper than this are refused (protects the stack)
+	binaryMaxDepth = 10000
+)
+
+// The kind of data, written before the data itself
+const (
+	binNil byte = iota
+	binFalse
+	binTrue
+	binInt
+	binInt8
+	binInt16
+	binInt32
+	binInt64
+	binUint
+	binUint8
+	binUint16
+	binUint32
+	binUint64
+	binFloat32
+	binFloat64
+	binString
+	binBytes
+	binTime
+	binDuration
+	binTyped // Registered type: name then json
+	binJSON  // Anything else as json
+)
+
+var errBinaryCorrupt = errors.New("gonode: corrupt binary data")
+
+// Secret writer of the binary format
+type binWriter struct {
+	buf     bytes.Buffer
+	strings map[string]int
+	table   []string
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (w *binWriter) uvarint(v uint64) {
+	w.buf.Write(w.scratch[:binary.PutUvarint(w.scratch[:], v)])
+}
+
+func (w *binWriter) varint(v int64) {
+	w.buf.Write(w.scratch[:binary.PutVarint(w.scratch[:], v)])
+}
+
+func (w *binWriter) bytes(b []byte) {
+	w.uvarint(uint64(len(b)))
+	w.buf.Write(b)
+}
+
+// Secret util for collecting the strings of the table
+func (w *binWriter) collect(n *Node) {
+	add := func(s string) {
+		if _, ok := w.strings[s]; !ok {
+			w.strings[s] = len(w.table)
+			w.table = append(w.table, s)
+		}
+	}
+	for _, tag := range n.tags {
+		add(tag)
+	}
+	for k, v := range n.attrs {
+		add(k)
+		add(v)
+	}
+	for _, kid := range n.children {
+		w.collect(kid)
+	}
+}
+
+func (w *binWriter) node(n *Node) error {
+	w.uvarint(uint64(len(n.tags)))
+	for _, tag := range n.tags {
+		w.uvarint(uint64(w.strings[tag]))
+	}
+	keys := make([]string, 0, len(n.attrs))
+	for k := range n.attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	w.uvarint(uint64(len(keys)))
+	for _, k := range keys {
+		w.uvarint(uint64(w.strings[k]))
+		w.uvarint(uint64(w.strings[n.attrs[k]]))
+	}
+	err := w.data(n.data)
+	if err != nil {
+		return err
+	}
+	w.uvarint(uint64(len(n.children)))
+	for _, kid := range n.children {
+		err = w.node(kid)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *binWriter) data(data any) error {
+	switch d := data.(type) {
+	case nil:
+		w.buf.WriteByte(binNil)
+	case bool:
+		if d {
+			w.buf.WriteByte(binTrue)
+		} else {
+			w.buf.WriteByte(binFalse)
+		}
+	case int:
+		w.buf.WriteByte(binInt)
+		w.varint(int64(d))
+	case int8:
+		w.buf.WriteByte(binInt8)
+		w.varint(int64(d))
+	case int16:
+		w.buf.WriteByte(binInt16)
+		w.varint(int64(d))
+	case int32:
+		w.buf.WriteByte(binInt32)
+		w.varint(int64(d))
+	case int64:
+		w.buf.WriteByte(binInt64)
+		w.varint(d)
+	case uint:
+		w.buf.WriteByte(binUint)
+		w.uvarint(uint64(d))
+	case uint8:
+		w.buf.WriteByte(binUint8)
+		w.uvarint(uint64(d))
+	case uint16:
+		w.buf.WriteByte(binUint16)
+		w.uvarint(uint64(d))
+	case uint32:
+		w.buf.WriteByte(binUint32)
+		w.uvarint(uint64(d))
+	case uint64:
+		w.buf.WriteByte(binUint64)
+		w.uvarint(d)
+	case float32:
+		w.buf.WriteByte(binFloat32)
+		w.buf.Write(binary.LittleEndian.AppendUint32(nil, math.Float32bits(d)))
+	case float64:
+		w.buf.WriteByte(binFloat64)
+		w.buf.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(d)))
+	case string:
+		w.buf.WriteByte(binString)
+		w.bytes([]byte(d))
+	case []byte:
+		w.buf.WriteByte(binBytes)
+		w.bytes(d)
+	case time.Time:
+		pay, err := d.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		w.buf.WriteByte(binTime)
+		w.bytes(pay)
+	case time.Duration:
+		w.buf.WriteByte(binDuration)
+		w.varint(int64(d))
+	default:
+		pay, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if name := typeName(data); name != "" {
+			w.buf.WriteByte(binTyped)
+			w.bytes([]byte(name))
+		} else {
+			w.buf.WriteByte(binJSON)
+		}
+		w.bytes(pay)
+	}
+	return nil
+}
+
+// Custom Marshaler for the compact binary format
+//
+// Data keeps it's Go type for bool, numbers, string, []byte, time.Time, time.Duration and registered types (see RegisterType),
+// anything else is stored as json
+func (n *Node) MarshalBinary() ([]byte, error) {
+	w := &binWriter{strings: map[string]int{}}
+	w.collect(n)
+	w.buf.WriteString(binaryMagic)
+	w.buf.WriteByte(binaryVersion)
+	w.uvarint(uint64(len(w.table)))
+	for _, s := range w.table {
+		w.bytes([]byte(s))
+	}
+	err := w.node(n)
+	if err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// Secret reader of the binary format
+type binReader struct {
+	r     *bytes.Reader
+	table []string
+}
+
+func (r *binReader) uvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, errBinaryCorrupt
+	}
+	return v, nil
+}
+
+func (r *binReader) varint() (int64, error) {
+	v, err := binary.ReadVarint(r.r)
+	if err != nil {
+		return 0, errBinaryCorrupt
+	}
+	return v, nil
+}
+
+// Secret util for reading a count, which can't be more than the bytes left
+func (r *binReader) count() (int, error) {
+	v, err := r.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if v > uint64(r.r.Len()) {
+		return 0, errBinaryCorrupt
+	}
+	return int(v), nil
+}
+
+func (r *binReader) bytes() ([]byte, error) {
+	size, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, size)
+	_, err = io.ReadFull(r.r, b)
+	if err != nil {
+		return nil, errBinaryCorrupt
+	}
+	return b, nil
+}
+
+func (r *binReader) str() (string, error) {
+	idx, err := r.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if idx >= uint64(len(r.table)) {
+		return "", errBinaryCorrupt
+	}
+	return r.table[idx], nil
+}
+
+func (r *binReader) node(o *Node, depth int) error {
+	if depth > binaryMaxDepth {
+		return fmt.Errorf("gonode: binary data nested deeper than %d", binaryMaxDepth)
+	}
+	count, err := r.count()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i += 1 {
+		tag, err := r.str()
+		if err != nil {
+			return err
+		}
+		o.AddTag(tag)
+	}
+	count, err = r.count()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i += 1 {
+		k, err := r.str()
+		if err != nil {
+			return err
+		}
+		v, err := r.str()
+		if err != nil {
+			return err
+		}
+		o.SetAttr(k, v)
+	}
+	o.data, err = r.data()
+	if err != nil {
+		return err
+	}
+	count, err = r.count()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i += 1 {
+		err = r.node(o.NewChild(), depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *binReader) data() (any, error) {
+	kind, err := r.r.ReadByte()
+	if err != nil {
+		return nil, errBinaryCorrupt
+	}
+	switch kind {
+	case binNil:
+		return nil, nil
+	case binFalse:
+		return false, nil
+	case binTrue:
+		return true, nil
+	case binInt, binInt8, binInt16, binInt32, binInt64, binDuration:
+		v, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case binInt:
+			return int(v), nil
+		case binInt8:
+			return int8(v), nil
+		case binInt16:
+			return int16(v), nil
+		case binInt32:
+			return int32(v), nil
+		case binDuration:
+			return time.Duration(v), nil
+		}
+		return v, nil
+	case binUint, binUint8, binUint16, binUint32, binUint64:
+		v, err := r.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case binUint:
+			return uint(v), nil
+		case binUint8:
+			return uint8(v), nil
+		case binUint16:
+			return uint16(v), nil
+		case binUint32:
+			return uint32(v), nil
+		}
+		return v, nil
+	case binFloat32:
+		b := make([]byte, 4)
+		if _, err := io.ReadFull(r.r, b); err != nil {
+			return nil, errBinaryCorrupt
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+	case binFloat64:
+		b := make([]byte, 8)
+		if _, err := io.ReadFull(r.r, b); err != nil {
+			return nil, errBinaryCorrupt
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case binString:
+		b, err := r.bytes()
+		return string(b), err
+	case binBytes:
+		return r.bytes()
+	case binTime:
+		b, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		t := time.Time{}
+		err = t.UnmarshalBinary(b)
+		if err != nil {
+			return nil, errBinaryCorrupt
+		}
+		return t, nil
+	case binTyped, binJSON:
+		name := ""
+		if kind == binTyped {
+			b, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			name = string(b)
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return decodeData(b, name)
+	}
+	return nil, errBinaryCorrupt
+}
+
+// Custom Unmarshaler for the compact binary format (from MarshalBinary)
+//
+// This Node's content is replaced, on error this Node is left untouched
+func (n *Node) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) || len(data) < len(binaryMagic)+1 {
+		return errors.New("gonode: not binary Node data")
+	}
+	if v := data[len(binaryMagic)]; v != binaryVersion {
+		return fmt.Errorf("gonode: unsupported binary version %d", v)
+	}
+	r := &binReader{r: bytes.NewReader(data[len(binaryMagic)+1:])}
+	count, err := r.count()
+	if err != nil {
+		return err
+	}
+	r.table = make([]string, 0, count)
+	for i := 0; i < count; i += 1 {
+		b, err := r.bytes()
+		if err != nil {
+			return err
+		}
+		r.table = append(r.table, string(b))
+	}
+	o := &Node{}
+	err = r.node(o, 0)
+	if err != nil {
+		return err
+	}
+	if r.r.Len() != 0 {
+		return errBinaryCorrupt
+	}
+	n.Merge(o, ModeReplace)
+	return nil
+}
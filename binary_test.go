@@ -0,0 +1,124 @@
+package gonode_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/beanzilla/gonode"
+)
+
+// Secret util to build a tree with a bit of everything
+func sampleTree(size int) *gonode.Node {
+	n := gonode.NewNode()
+	for i := 0; i < size; i += 1 {
+		kid := n.NewChildWithDataAndTags(fmt.Sprintf("item %d", i), "item", "generated")
+		kid.SetAttr("unit", "celsius")
+		kid.NewChildWithDataAndTags(i, "count")
+		kid.NewChildWithDataAndTags(float64(i)/3, "ratio")
+		kid.NewChildWithDataAndTags(i%2 == 0, "even")
+	}
+	return n
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	when := time.Date(2022, 10, 12, 21, 10, 6, 0, time.UTC)
+	n := gonode.NewNode()
+	values := []any{
+		42, int8(-8), int16(16), int32(-32), int64(1<<62 + 1),
+		uint(7), uint8(8), uint16(16), uint32(32), uint64(1<<63 + 1),
+		float32(3.14), 9.81, "Hello World", []byte{0, 255}, when, time.Minute,
+		true, false, map[string]any{"plain": "json"},
+	}
+	for idx, v := range values {
+		n.NewChildWithDataAndTags(v, fmt.Sprint(idx)).SetAttr("type", fmt.Sprintf("%T", v))
+	}
+	n.NewChildWithTags("empty").NewChildWithTags("nested")
+
+	pay, err := n.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary %v", err)
+	}
+	dummy := gonode.NewNodeWithData("old")
+	err = dummy.UnmarshalBinary(pay)
+	if err != nil {
+		t.Fatalf("UnmarshalBinary %v", err)
+	}
+	if !dummy.HasTag("root") || dummy.Data() != nil || dummy.Len() != n.Len() {
+		t.Fatalf("Expected the same tree back")
+	}
+	for idx, v := range values {
+		got := dummy.Child(idx)
+		if !reflect.DeepEqual(got.Data(), v) {
+			t.Errorf("Expected %#v, got %#v", v, got.Data())
+		}
+		if !got.HasAttr("type", fmt.Sprintf("%T", v)) || got.Parent() != dummy {
+			t.Errorf("Expected tags, attributes and parent kept for %#v", v)
+		}
+	}
+	if dummy.ChildByTagDeep("nested") == nil {
+		t.Errorf("Expected nested children")
+	}
+
+	// Corrupt input shouldn't panic (nor be accepted)
+	for size := 0; size < len(pay); size += 1 {
+		if err := gonode.NewNode().UnmarshalBinary(pay[:size]); err == nil {
+			t.Errorf("Expected error for data cut at %d bytes", size)
+		}
+	}
+}
+
+func TestBinarySize(t *testing.T) {
+	n := sampleTree(1000)
+	bin, err := n.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary %v", err)
+	}
+	js, err := json.Marshal(n)
+	if err != nil {
+		t.Fatalf("json.Marshal %v", err)
+	}
+	if len(bin)*3 > len(js) {
+		t.Errorf("Expected binary (%d bytes) to be at least 3 times smaller than json (%d bytes)", len(bin), len(js))
+	}
+}
+
+func BenchmarkMarshalBinary(b *testing.B) {
+	n := sampleTree(1000)
+	for i := 0; i < b.N; i += 1 {
+		if _, err := n.MarshalBinary(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	n := sampleTree(1000)
+	for i := 0; i < b.N; i += 1 {
+		if _, err := json.Marshal(n); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalBinary(b *testing.B) {
+	pay, _ := sampleTree(1000).MarshalBinary()
+	b.ResetTimer()
+	for i := 0; i < b.N; i += 1 {
+		if err := gonode.NewNode().UnmarshalBinary(pay); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalJSON(b *testing.B) {
+	pay, _ := json.Marshal(sampleTree(1000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i += 1 {
+		if err := gonode.NewNode().UnmarshalJSON(pay); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package gonode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+// Marshals this Node to it's canonical json, identical trees always give identical bytes (for cache keys or signing)
+//
+// Uses the same layout as MarshalJSON (so UnmarshalJSON reads it back) but with the data normalized:
+// object keys are sorted, numbers are written the same way no matter how they were given (1, 1.0 and 1e0 are all 1,
+// whole numbers are always written as integers)
+// and nothing is escaped for html. Tags keep their order, use an Encoder with SetSortTags when it doesn't matter
+func (n *Node) MarshalCanonicalJSON() ([]byte, error) {
+	enc := &Encoder{}
+	enc.SetCanonical(true)
+	return enc.bytes(n)
+}
+
+// Secret util for the canonical json of a value
+func canonicalJSON(v any) ([]byte, error) {
+	pay, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// Back into plain json values (structs and typed maps become map[string]any)
+	dec := json.NewDecoder(bytes.NewReader(pay))
+	dec.UseNumber()
+	var val any
+	err = dec.Decode(&val)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	err = writeCanonical(buf, val)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Secret util for writing a plain json value (from decoding with UseNumber) canonically
+func writeCanonical(buf *bytes.Buffer, v any) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(val))
+	case string:
+		writeCanonicalString(buf, val)
+	case json.Number:
+		num, err := canonicalNumber(val)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(num)
+	case []any:
+		buf.WriteByte('[')
+		for idx, item := range val {
+			if idx != 0 {
+				buf.WriteByte(',')
+			}
+			err := writeCanonical(buf, item)
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for idx, k := range keys {
+			if idx != 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			err := writeCanonical(buf, val[k])
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	}
+	return nil
+}
+
+// Secret util for writing a json string without escaping for html
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s) // Can't fail for a string
+	buf.Truncate(buf.Len() - 1)
+}
+
+// Secret util for normalizing a json number
+//
+// Whole numbers are written as integers no matter their size or how they were given (so 1152921504606846976 and
+// 1.152921504606846976e18 match), others as float64 would be by json.Marshal (shortest form).
+// A number written as json.Marshal writes a float64 stands for that float64, so float64(1<<60) matches int64(1<<60)
+func canonicalNumber(num json.Number) (string, error) {
+	s := string(num)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", err
+	}
+	if f == 0 {
+		return "0", nil // Also turns -0 into 0
+	}
+	if f == math.Trunc(f) {
+		// Only whole floats can be whole numbers, and being non-zero keeps the exponent (so the work) small
+		exact, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return "", fmt.Errorf("invalid number %s", s)
+		}
+		short, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+		if exact.Cmp(short) == 0 {
+			exact.SetFloat64(f)
+		}
+		if exact.IsInt() {
+			return exact.Num().String(), nil
+		}
+	}
+	pay, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	return string(pay), nil
+}
@@ -0,0 +1,140 @@
+package gonode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	type point struct {
+		Y, X int
+	}
+	a := gonode.NewNode()
+	a.SetAttr("b", "2")
+	a.SetAttr("a", "1")
+	a.NewChildWithData(map[string]any{"z": 1.0, "a": []any{"<b>", 2.5}})
+	a.NewChildWithData(point{Y: 2, X: 1})
+
+	b := gonode.NewNode()
+	err := b.UnmarshalJSON([]byte(`{"Children":[{"Data":{"a":["<b>",25e-1],"z":1e0}},{"Data":{"X":1.0,"Y":2}}],"Attrs":{"a":"1","b":"2"},"Tags":["root"]}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON %v", err)
+	}
+	pa, err := a.MarshalCanonicalJSON()
+	if err != nil {
+		t.Fatalf("MarshalCanonicalJSON %v", err)
+	}
+	pb, err := b.MarshalCanonicalJSON()
+	if err != nil {
+		t.Fatalf("MarshalCanonicalJSON %v", err)
+	}
+	want := `{"Tags":["root"],"Attrs":{"a":"1","b":"2"},"Children":[{"Data":{"a":["<b>",2.5],"z":1}},{"Data":{"X":1,"Y":2}}]}`
+	if string(pa) != want {
+		t.Errorf("Expected %s, got %s", want, pa)
+	}
+	if !bytes.Equal(pa, pb) {
+		t.Errorf("Expected identical trees to give identical bytes, got %s and %s", pa, pb)
+	}
+
+	// Reads back
+	c := gonode.NewNode()
+	err = c.UnmarshalJSON(pa)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON %v", err)
+	}
+	pc, _ := c.MarshalCanonicalJSON()
+	if !bytes.Equal(pa, pc) {
+		t.Errorf("Expected %s, got %s", pa, pc)
+	}
+}
+
+func TestCanonicalNumbers(t *testing.T) {
+	tests := map[string]string{
+		`1`:    `1`,
+		`1.0`:  `1`,
+		`1e0`:  `1`,
+		`-0`:   `0`,
+		`-0.0`: `0`,
+		`0.5`:  `0.5`,
+		`5e-1`: `0.5`,
+		`1e21`: `1000000000000000000000`,
+		`1e23`: `99999999999999991611392`, // The float64 closest to 1e23
+		`-1e3`: `-1000`,
+		`1.5`:  `1.5`,
+		`1e-7`: `1e-7`,
+	}
+	for in, want := range tests {
+		n := gonode.NewNode()
+		err := n.UnmarshalJSON([]byte(`{"Data":` + in + `}`))
+		if err != nil {
+			t.Fatalf("UnmarshalJSON %s %v", in, err)
+		}
+		pay, err := n.MarshalCanonicalJSON()
+		if err != nil {
+			t.Fatalf("MarshalCanonicalJSON %s %v", in, err)
+		}
+		if got := string(pay); got != `{"Data":`+want+`}` {
+			t.Errorf("%s: expected %s, got %s", in, want, got)
+		}
+	}
+
+	// Whole numbers are integers whatever their size or form
+	for _, in := range []string{`1152921504606846976`, `1.152921504606846976e18`, `115292150460684697.6e1`} {
+		pay, err := gonode.NewNodeWithData(json.RawMessage(in)).MarshalCanonicalJSON()
+		if err != nil {
+			t.Fatalf("MarshalCanonicalJSON %s %v", in, err)
+		}
+		if want := `{"Tags":["root"],"Data":1152921504606846976}`; string(pay) != want {
+			t.Errorf("%s: expected %s, got %s", in, want, pay)
+		}
+	}
+	a, _ := gonode.NewNodeWithData(int64(1 << 60)).MarshalCanonicalJSON()
+	b, _ := gonode.NewNodeWithData(float64(1 << 60)).MarshalCanonicalJSON()
+	if !bytes.Equal(a, b) {
+		t.Errorf("Expected int64 and float64 data of the same value to match, got %s and %s", a, b)
+	}
+	pay, _ := gonode.NewNodeWithData(int64(1 << 60)).MarshalJSON()
+	c := gonode.NewNode()
+	err := c.UnmarshalJSON(pay)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON %v", err)
+	}
+	if pc, _ := c.MarshalCanonicalJSON(); !bytes.Equal(a, pc) {
+		t.Errorf("Expected %s after a json round trip, got %s", a, pc)
+	}
+
+	// Integers too large for a float64 keep their precision
+	pay, err = gonode.NewNodeWithData(uint64(12345678901234567890)).MarshalCanonicalJSON()
+	if err != nil {
+		t.Fatalf("MarshalCanonicalJSON %v", err)
+	}
+	if want := `{"Tags":["root"],"Data":12345678901234567890}`; string(pay) != want {
+		t.Errorf("Expected %s, got %s", want, pay)
+	}
+}
+
+func TestSortTags(t *testing.T) {
+	a := gonode.NewNodeWithTags("b", "a", "c")
+	b := gonode.NewNodeWithTags("c", "b", "a")
+	encode := func(n *gonode.Node) string {
+		buf := &strings.Builder{}
+		enc := gonode.NewEncoder(buf)
+		enc.SetCanonical(true)
+		enc.SetSortTags(true)
+		err := enc.Encode(n)
+		if err != nil {
+			t.Fatalf("Encode %v", err)
+		}
+		return buf.String()
+	}
+	if encode(a) != encode(b) || encode(a) != "{\"Tags\":[\"a\",\"b\",\"c\",\"root\"]}\n" {
+		t.Errorf("Expected sorted tags, got %q and %q", encode(a), encode(b))
+	}
+	if a.Tags()[1] != "b" {
+		t.Errorf("Expected the Node's own tags untouched, got %v", a.Tags())
+	}
+}
@@ -0,0 +1,322 @@
+package gonode
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Secret envelope for the cbor, msgpack and gob codecs, the same layout as MarshalJSON
+//
+// Only points at the Node's tags, attributes and data (nothing is copied)
+type envelope struct {
+	Tags     []string          `cbor:"Tags,omitempty" msgpack:"Tags,omitempty"`
+	Attrs    map[string]string `cbor:"Attrs,omitempty" msgpack:"Attrs,omitempty"`
+	Data     any               `cbor:"Data,omitempty" msgpack:"Data,omitempty"`
+	Children []*envelope       `cbor:"Children,omitempty" msgpack:"Children,omitempty"`
+}
+
+// Custom encoder for msgpack, as msgpack's omitempty would drop data of 0 or false
+func (e *envelope) EncodeMsgpack(enc *msgpack.Encoder) error {
+	fields := map[string]bool{
+		"Tags":     len(e.Tags) != 0,
+		"Attrs":    len(e.Attrs) != 0,
+		"Data":     e.Data != nil,
+		"Children": len(e.Children) != 0,
+	}
+	count := 0
+	for _, has := range fields {
+		if has {
+			count += 1
+		}
+	}
+	err := enc.EncodeMapLen(count)
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{"Tags", "Attrs", "Data", "Children"} {
+		if !fields[key] {
+			continue
+		}
+		err = enc.EncodeString(key)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "Tags":
+			err = enc.Encode(e.Tags)
+		case "Attrs":
+			err = enc.Encode(e.Attrs)
+		case "Data":
+			err = enc.Encode(e.Data)
+		case "Children":
+			err = enc.Encode(e.Children)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Secret util that widens the smallest fitting integers msgpack decodes to int64 (or uint64)
+func widenMsgpack(v any) any {
+	switch d := v.(type) {
+	case int8:
+		return int64(d)
+	case int16:
+		return int64(d)
+	case int32:
+		return int64(d)
+	case uint8:
+		return int64(d)
+	case uint16:
+		return int64(d)
+	case uint32:
+		return int64(d)
+	case uint64:
+		if d <= math.MaxInt64 {
+			return int64(d)
+		}
+	case []any:
+		for idx := range d {
+			d[idx] = widenMsgpack(d[idx])
+		}
+	case map[string]any:
+		for k := range d {
+			d[k] = widenMsgpack(d[k])
+		}
+	}
+	return v
+}
+
+// Secret util for making the envelope of this Node (and it's children)
+func (n *Node) envelope() *envelope {
+	e := &envelope{
+		Tags:  n.tags,
+		Attrs: n.attrs,
+		Data:  n.data,
+	}
+	for _, kid := range n.children {
+		e.Children = append(e.Children, kid.envelope())
+	}
+	return e
+}
+
+// Secret util for calling fn on this envelope and everything below it
+func (e *envelope) walk(fn func(e *envelope)) {
+	fn(e)
+	for _, kid := range e.Children {
+		if kid != nil {
+			kid.walk(fn)
+		}
+	}
+}
+
+// Secret util for making a new Node from the given envelope
+func (e *envelope) node(path string) (*Node, error) {
+	o := &Node{}
+	o.AddTag(e.Tags...)
+	for k, v := range e.Attrs {
+		o.SetAttr(k, v)
+	}
+	err := o.SetData(e.Data)
+	if err != nil {
+		return nil, &DecodeError{Path: joinPath(path, "Data"), Err: err}
+	}
+	for idx, kid := range e.Children {
+		if kid == nil {
+			return nil, &DecodeError{Path: fmt.Sprintf("%s[%d]", joinPath(path, "Children"), idx), Err: errExpectedObject}
+		}
+		k, err := kid.node(fmt.Sprintf("%s[%d]", joinPath(path, "Children"), idx))
+		if err != nil {
+			return nil, err
+		}
+		o.AddChild(k)
+	}
+	return o, nil
+}
+
+// How deep cbor can nest without Limits, each level of a tree is 2 levels of cbor (the envelope and it's children)
+// so this allows trees about 500 levels deep
+const cborMaxNestedLevels = 1024
+
+// Secret util for the cbor decoding mode, maps decode as map[string]any (like json) rather than map[any]any
+//
+// MaxDepth and MaxChildren become cbor's nesting and array limits (so they bound arrays in data too),
+// otherwise the cbor library's defaults are kept (with cborMaxNestedLevels for nesting)
+func cborDecMode(limits Limits) (cbor.DecMode, error) {
+	opts := cbor.DecOptions{
+		DefaultMapType:  reflect.TypeOf(map[string]any{}),
+		MaxNestedLevels: cborMaxNestedLevels,
+	}
+	if limits.MaxDepth > 0 {
+		// Room for data nested below the deepest Node too
+		opts.MaxNestedLevels = clampInt(2*(limits.MaxDepth+1)+32, 4, 65535)
+	}
+	if limits.MaxChildren > 0 {
+		opts.MaxArrayElements = clampInt(limits.MaxChildren, 16, math.MaxInt32)
+	}
+	return opts.DecMode()
+}
+
+// Secret util for keeping a value within the given range
+func clampInt(v, low, high int) int {
+	if v < low {
+		return low
+	}
+	if v > high {
+		return high
+	}
+	return v
+}
+
+// Secret util for checking this envelope (and everything below it) against the given limits
+//
+// Going over a limit returns a *DecodeError wrapping a *LimitError, like the json Decoder
+func (e *envelope) limit(limits Limits, path string, depth int, nodes *int) error {
+	check := func(path, name string, max, value int) error {
+		if max > 0 && value > max {
+			return &DecodeError{Path: path, Err: &LimitError{Limit: name, Max: max}}
+		}
+		return nil
+	}
+	*nodes += 1
+	err := check(path, "MaxNodes", limits.MaxNodes, *nodes)
+	if err == nil {
+		err = check(path, "MaxDepth", limits.MaxDepth, depth)
+	}
+	if err == nil {
+		err = check(joinPath(path, "Children"), "MaxChildren", limits.MaxChildren, len(e.Children))
+	}
+	for idx := 0; err == nil && idx < len(e.Tags); idx += 1 {
+		err = check(fmt.Sprintf("%s[%d]", joinPath(path, "Tags"), idx), "MaxTagLength", limits.MaxTagLength, len(e.Tags[idx]))
+	}
+	for k, v := range e.Attrs {
+		if err == nil {
+			err = check(joinPath(path, "Attrs"), "MaxTagLength", limits.MaxTagLength, len(k))
+		}
+		if err == nil {
+			err = check(joinPath(joinPath(path, "Attrs"), k), "MaxTagLength", limits.MaxTagLength, len(v))
+		}
+	}
+	if err == nil && limits.MaxDataSize > 0 && e.Data != nil {
+		pay, _ := cbor.Marshal(e.Data) // Came from cbor, so goes back
+		err = check(joinPath(path, "Data"), "MaxDataSize", limits.MaxDataSize, len(pay))
+	}
+	if err != nil {
+		return err
+	}
+	for idx, kid := range e.Children {
+		if kid == nil {
+			continue // Reported by node
+		}
+		err = kid.limit(limits, fmt.Sprintf("%s[%d]", joinPath(path, "Children"), idx), depth+1, nodes)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Custom Marshaler for cbor (using the same layout as MarshalJSON)
+//
+// Unlike json, integers stay integers and []byte stays a byte string
+func (n *Node) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(n.envelope())
+}
+
+// Custom Unmarshaler for cbor (using the same layout as MarshalJSON)
+//
+// Integers come back as uint64 (or int64 when negative), this Node's content is replaced.
+// Nesting is limited to cborMaxNestedLevels, use UnmarshalCBORLimits for input that can't be trusted
+func (n *Node) UnmarshalCBOR(data []byte) error {
+	return n.UnmarshalCBORLimits(data, Limits{})
+}
+
+// Unmarshals cbor (from MarshalCBOR) into this Node, checking the given limits (see Limits)
+//
+// MaxDataSize counts bytes of cbor rather than json, going over a limit returns a *DecodeError wrapping a *LimitError
+func (n *Node) UnmarshalCBORLimits(data []byte, limits Limits) error {
+	dm, err := cborDecMode(limits)
+	if err != nil {
+		return err
+	}
+	e := &envelope{}
+	err = dm.Unmarshal(data, e)
+	if err != nil {
+		// cbor's own limits standing in for ours
+		var nestErr *cbor.MaxNestedLevelError
+		var arrayErr *cbor.MaxArrayElementsError
+		switch {
+		case limits.MaxDepth > 0 && errors.As(err, &nestErr):
+			return &DecodeError{Err: &LimitError{Limit: "MaxDepth", Max: limits.MaxDepth}}
+		case limits.MaxChildren > 0 && errors.As(err, &arrayErr):
+			return &DecodeError{Err: &LimitError{Limit: "MaxChildren", Max: limits.MaxChildren}}
+		}
+		return err
+	}
+	nodes := 0
+	err = e.limit(limits, "", 0, &nodes)
+	if err != nil {
+		return err
+	}
+	e.walk(func(e *envelope) {
+		e.Data = pointBigInts(e.Data)
+	})
+	o, err := e.node("")
+	if err != nil {
+		return err
+	}
+	n.Merge(o, ModeReplace)
+	return nil
+}
+
+// Secret util that turns the big.Int values cbor decodes bignums to into *big.Int (like json gives)
+func pointBigInts(v any) any {
+	switch d := v.(type) {
+	case big.Int:
+		return &d
+	case []any:
+		for idx := range d {
+			d[idx] = pointBigInts(d[idx])
+		}
+	case map[string]any:
+		for k := range d {
+			d[k] = pointBigInts(d[k])
+		}
+	}
+	return v
+}
+
+// Custom Marshaler for msgpack (using the same layout as MarshalJSON)
+//
+// Unlike json, integers stay integers and []byte stays a byte string
+func (n *Node) MarshalMsgpack() ([]byte, error) {
+	return msgpack.Marshal(n.envelope())
+}
+
+// Custom Unmarshaler for msgpack (using the same layout as MarshalJSON)
+//
+// Integers come back as int64 (or uint64 when too large), this Node's content is replaced
+func (n *Node) UnmarshalMsgpack(data []byte) error {
+	e := &envelope{}
+	err := msgpack.Unmarshal(data, e)
+	if err != nil {
+		return err
+	}
+	e.walk(func(e *envelope) {
+		e.Data = widenMsgpack(e.Data)
+	})
+	o, err := e.node("")
+	if err != nil {
+		return err
+	}
+	n.Merge(o, ModeReplace)
+	return nil
+}
@@ -0,0 +1,186 @@
+package gonode_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestCBORAndMsgpack(t *testing.T) {
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags(42, "int")
+	n.NewChildWithDataAndTags(-7, "negative")
+	n.NewChildWithDataAndTags(0, "zero")
+	n.NewChildWithDataAndTags(false, "false")
+	n.NewChildWithDataAndTags(9.81, "float")
+	n.NewChildWithDataAndTags(2.0, "whole float")
+	n.NewChildWithDataAndTags([]byte{0, 1, 255}, "bytes")
+	n.NewChildWithDataAndTags(map[string]any{"k": "v"}, "map")
+	lvl := n.NewChildWithTags("level 2")
+	lvl.SetAttr("unit", "celsius")
+	lvl.NewChildWithTags("a")
+	lvl.NewChildWithTags("b")
+
+	codecs := map[string]struct {
+		marshal   func(v any) ([]byte, error)
+		unmarshal func(data []byte, v any) error
+		ints      []any
+	}{
+		"cbor":    {cbor.Marshal, cbor.Unmarshal, []any{uint64(42), int64(-7), uint64(0)}},
+		"msgpack": {msgpack.Marshal, msgpack.Unmarshal, []any{int64(42), int64(-7), int64(0)}},
+	}
+	for name, c := range codecs {
+		pay, err := c.marshal(n)
+		if err != nil {
+			t.Fatalf("%s marshal %v", name, err)
+		}
+		dummy := gonode.NewNode()
+		err = c.unmarshal(pay, dummy)
+		if err != nil {
+			t.Fatalf("%s unmarshal %v", name, err)
+		}
+		for idx, want := range c.ints {
+			if got := dummy.Child(idx).Data(); got != want {
+				t.Errorf("%s: expected %#v, got %#v", name, want, got)
+			}
+		}
+		checks := map[string]any{
+			"false":       false,
+			"float":       9.81,
+			"whole float": 2.0,
+			"bytes":       []byte{0, 1, 255},
+			"map":         map[string]any{"k": "v"},
+		}
+		for tag, want := range checks {
+			if got := dummy.ChildByTag(tag).Data(); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: expected %#v for '%s', got %#v", name, want, tag, got)
+			}
+		}
+		kid := dummy.ChildByTag("level 2")
+		if !kid.HasAttr("unit", "celsius") || kid.Len() != 2 || kid.Child(1).HasTag("a") || kid.Parent() != dummy {
+			t.Errorf("%s: expected attributes, child order and parents kept", name)
+		}
+	}
+}
+
+func TestCBORMatchesJSONLayout(t *testing.T) {
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags("Hello World", "hello")
+	pay, err := cbor.Marshal(n)
+	if err != nil {
+		t.Fatalf("cbor.Marshal %v", err)
+	}
+	var generic any
+	err = cbor.Unmarshal(pay, &generic)
+	if err != nil {
+		t.Fatalf("cbor.Unmarshal %v", err)
+	}
+	js, _ := json.Marshal(n)
+	var want map[string]any
+	_ = json.Unmarshal(js, &want)
+	got := map[string]any{}
+	for k, v := range generic.(map[any]any) {
+		got[k.(string)] = v
+	}
+	if len(got) != len(want) || got["Tags"] == nil || got["Children"] == nil {
+		t.Errorf("Expected the json layout, got %#v", got)
+	}
+}
+
+func TestCBORAndMsgpackDeep(t *testing.T) {
+	n := gonode.NewNode()
+	at := n
+	for i := 0; i < 100; i += 1 {
+		at = at.NewChildWithTags("deeper")
+	}
+	at.SetData("bottom")
+
+	pay, err := n.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("MarshalCBOR %v", err)
+	}
+	dummy := gonode.NewNode()
+	err = dummy.UnmarshalCBOR(pay)
+	if err != nil {
+		t.Fatalf("UnmarshalCBOR %v", err)
+	}
+	checkBottom(t, "cbor", dummy)
+
+	pay, err = msgpack.Marshal(n)
+	if err != nil {
+		t.Fatalf("msgpack marshal %v", err)
+	}
+	dummy = gonode.NewNode()
+	err = msgpack.Unmarshal(pay, dummy)
+	if err != nil {
+		t.Fatalf("msgpack unmarshal %v", err)
+	}
+	checkBottom(t, "msgpack", dummy)
+}
+
+// Secret util to check the bottom of a deep tree made by TestCBORAndMsgpackDeep
+func checkBottom(t *testing.T, name string, n *gonode.Node) {
+	at := n
+	for at.Len() != 0 {
+		at = at.Child(0)
+	}
+	if at.Depth() != 100 || at.Data() != "bottom" {
+		t.Errorf("%s: expected the bottom at depth 100, got %d", name, at.Depth())
+	}
+}
+
+func TestUnmarshalCBORLimits(t *testing.T) {
+	deep := gonode.NewNode()
+	at := deep
+	for i := 0; i < 600; i += 1 {
+		at = at.NewChildWithTags("deeper")
+	}
+	deepPay, err := deep.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("MarshalCBOR %v", err)
+	}
+	if err := gonode.NewNode().UnmarshalCBOR(deepPay); err == nil {
+		t.Errorf("Expected the default nesting limit to refuse a tree 600 levels deep")
+	}
+	if err := gonode.NewNode().UnmarshalCBORLimits(deepPay, gonode.Limits{MaxDepth: 1000}); err != nil {
+		t.Errorf("Expected MaxDepth to raise the nesting limit, got %v", err)
+	}
+
+	wide := gonode.NewNode()
+	for i := 0; i < 100; i += 1 {
+		wide.NewChildWithTags("this tag is long")
+	}
+	wide.Child(0).SetData(strings.Repeat("x", 100))
+	pay, err := wide.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("MarshalCBOR %v", err)
+	}
+	cases := []struct {
+		pay    []byte
+		limits gonode.Limits
+		limit  string
+	}{
+		{deepPay, gonode.Limits{MaxDepth: 10}, "MaxDepth"},
+		{deepPay, gonode.Limits{MaxDepth: 599}, "MaxDepth"},
+		{pay, gonode.Limits{MaxChildren: 10}, "MaxChildren"},
+		{pay, gonode.Limits{MaxNodes: 50}, "MaxNodes"},
+		{pay, gonode.Limits{MaxTagLength: 8}, "MaxTagLength"},
+		{pay, gonode.Limits{MaxDataSize: 50}, "MaxDataSize"},
+	}
+	for _, c := range cases {
+		err := gonode.NewNode().UnmarshalCBORLimits(c.pay, c.limits)
+		var le *gonode.LimitError
+		if !errors.As(err, &le) || le.Limit != c.limit {
+			t.Errorf("Expected %s to be exceeded, got %v", c.limit, err)
+		}
+	}
+	if err := gonode.NewNode().UnmarshalCBORLimits(pay, gonode.Limits{MaxChildren: 100, MaxNodes: 101, MaxTagLength: 16, MaxDataSize: 200}); err != nil {
+		t.Errorf("Expected to decode within the limits, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package gonode_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestDecoderLimits(t *testing.T) {
+	deep := strings.Repeat(`{"Children": [`, 100) + `{}` + strings.Repeat(`]}`, 100)
+	wide := `{"Children": [` + strings.Repeat(`{}, `, 99) + `{}]}`
+	cases := []struct {
+		doc    string
+		limits gonode.Limits
+		limit  string
+	}{
+		{deep, gonode.Limits{MaxDepth: 10}, "MaxDepth"},
+		{wide, gonode.Limits{MaxChildren: 10}, "MaxChildren"},
+		{wide, gonode.Limits{MaxNodes: 50}, "MaxNodes"},
+		{`{"Tags": ["short", "this one is too long"]}`, gonode.Limits{MaxTagLength: 8}, "MaxTagLength"},
+		{`{"Attrs": {"unit": "this one is too long"}}`, gonode.Limits{MaxTagLength: 8}, "MaxTagLength"},
+		{`{"Data": "this one is too long"}`, gonode.Limits{MaxDataSize: 8}, "MaxDataSize"},
+		{`{"Extra": "this one is too long"}`, gonode.Limits{MaxDataSize: 8}, "MaxDataSize"},
+		{`{"Type": "this one is too long", "Data": 1}`, gonode.Limits{MaxTagLength: 8}, "MaxTagLength"},
+	}
+	for _, c := range cases {
+		dec := gonode.NewDecoder(strings.NewReader(c.doc))
+		dec.SetLimits(c.limits)
+		_, err := dec.Decode()
+		var le *gonode.LimitError
+		if !errors.As(err, &le) {
+			t.Errorf("Expected *LimitError for %s, got %v", c.limit, err)
+			continue
+		}
+		if le.Limit != c.limit {
+			t.Errorf("Expected %s to be exceeded, got %s", c.limit, le.Limit)
+		}
+	}
+
+	// Within the limits
+	dec := gonode.NewDecoder(strings.NewReader(deep + wide))
+	dec.SetLimits(gonode.Limits{MaxDepth: 100, MaxChildren: 100, MaxNodes: 101})
+	for i := 0; i < 2; i += 1 {
+		n, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Decode %v", err)
+		}
+		if n == nil || !n.IsRoot() {
+			t.Errorf("Expected a root Node")
+		}
+	}
+	_, err := dec.Decode()
+	if err != io.EOF {
+		t.Errorf("Expected io.EOF after the last document, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package gonode
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// The data FromFS gives each Node, the metadata of a file (or directory)
+type FileMeta struct {
+	Name    string
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+}
+
+func init() {
+	// So FileMeta data keeps it's type through typed encoding (and gob)
+	err := registerType("gonode.FileMeta", FileMeta{})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Makes a new "root" Node from the given directory of a filesystem (use "." for all of it)
+//
+// Each file and directory becomes a child tagged by it's name, with it's FileMeta as data
+// (the "root" Node gets the FileMeta of the directory). Only the metadata is read, not what's in the files
+func FromFS(fsys fs.FS, root string) (*Node, error) {
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	n := NewNodeWithData(fileMeta(info))
+	if !info.IsDir() {
+		return n, nil
+	}
+	err = n.fromFS(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Secret util for the FileMeta of a fs.FileInfo
+func fileMeta(info fs.FileInfo) FileMeta {
+	return FileMeta{
+		Name:    info.Name(),
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+	}
+}
+
+// Secret util for adding the entries of the given directory as children
+func (n *Node) fromFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		kid := n.NewChildWithDataAndTags(fileMeta(info), entry.Name())
+		if entry.IsDir() {
+			err = kid.fromFS(fsys, path.Join(dir, entry.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Serves this Node (and everything below it) as a read only filesystem, so the tree works with
+// http.FileServer (through http.FS), fs.WalkDir, template.ParseFS and friends
+//
+// This Node is the top directory ("."), children are named by their first tag (children without one are left out).
+// Nodes with children (or a directory FileMeta) are directories, others are files holding their data:
+// []byte and string as is, FileMeta as nothing and anything else as json. Mode and ModTime come from a FileMeta
+//
+// The tree is read as files are opened, don't change it while the filesystem is in use
+func (n *Node) FS() fs.FS {
+	return nodeFS{n}
+}
+
+// Secret fs.FS over a Node
+type nodeFS struct {
+	n *Node
+}
+
+func (fsys nodeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	at := fsys.n
+	if name != "." {
+		for _, elem := range strings.Split(name, "/") {
+			if !at.isDir() {
+				return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+			}
+			at = at.keyChild(elem)
+			if at == nil {
+				return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+			}
+		}
+	}
+	info, content, err := at.fileInfo(path.Base(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if info.IsDir() {
+		return &nodeDir{n: at, info: info}, nil
+	}
+	return &nodeFile{Reader: bytes.NewReader(content), info: info}, nil
+}
+
+// Secret util for if this Node is served as a directory
+func (n *Node) isDir() bool {
+	if meta, ok := n.data.(FileMeta); ok && meta.Mode.IsDir() {
+		return true
+	}
+	return n.Len() != 0
+}
+
+// Secret util for the fs.FileInfo (and content, if a file) of this Node
+func (n *Node) fileInfo(name string) (fs.FileInfo, []byte, error) {
+	info := &nodeInfo{name: name, n: n}
+	meta, isMeta := n.data.(FileMeta)
+	if isMeta {
+		info.mode = meta.Mode
+		info.modTime = meta.ModTime
+	}
+	if n.isDir() {
+		info.mode = fs.ModeDir | info.mode.Perm()
+		if !isMeta {
+			info.mode |= 0555
+		}
+		return info, nil, nil
+	}
+	var content []byte
+	switch d := n.data.(type) {
+	case nil, FileMeta:
+	case []byte:
+		content = d
+	case string:
+		content = []byte(d)
+	default:
+		pay, err := json.Marshal(d)
+		if err != nil {
+			return nil, nil, err
+		}
+		content = pay
+	}
+	info.size = int64(len(content))
+	if !isMeta {
+		info.mode = 0444
+	}
+	info.mode &^= fs.ModeType // It's content is served as a regular file
+	return info, content, nil
+}
+
+// Secret fs.FileInfo of a Node
+type nodeInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+	n       *Node
+}
+
+func (i *nodeInfo) Name() string       { return i.name }
+func (i *nodeInfo) Size() int64        { return i.size }
+func (i *nodeInfo) Mode() fs.FileMode  { return i.mode }
+func (i *nodeInfo) ModTime() time.Time { return i.modTime }
+func (i *nodeInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *nodeInfo) Sys() any           { return i.n } // The Node itself
+
+// Secret fs.File of a Node served as a file (also a io.Seeker and io.ReaderAt, for http.FileServer)
+type nodeFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *nodeFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *nodeFile) Close() error               { return nil }
+
+// Secret fs.ReadDirFile of a Node served as a directory
+type nodeDir struct {
+	n       *Node
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *nodeDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *nodeDir) Close() error               { return nil }
+
+func (d *nodeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: fs.ErrInvalid}
+}
+
+func (d *nodeDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		d.entries = []fs.DirEntry{}
+		seen := map[string]bool{}
+		for _, kid := range d.n.children {
+			if len(kid.tags) == 0 {
+				continue
+			}
+			name := kid.tags[0]
+			if seen[name] || !fs.ValidPath(name) || strings.Contains(name, "/") || name == "." {
+				continue // Can't be opened (only the first of the same name can)
+			}
+			seen[name] = true
+			info, _, err := kid.fileInfo(name)
+			if err != nil {
+				return nil, err
+			}
+			d.entries = append(d.entries, fs.FileInfoToDirEntry(info))
+		}
+	}
+	left := d.entries[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.entries)
+		return left, nil
+	}
+	if len(left) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(left) {
+		count = len(left)
+	}
+	d.offset += count
+	return left[:count], nil
+}
@@ -0,0 +1,106 @@
+package gonode_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"text/template"
+	"time"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestFromFS(t *testing.T) {
+	when := time.Date(2022, 10, 12, 21, 10, 6, 0, time.UTC)
+	mfs := fstest.MapFS{
+		"a.txt":         {Data: []byte("hello"), Mode: 0644, ModTime: when},
+		"dir/b.txt":     {Data: []byte("world!"), Mode: 0600},
+		"dir/sub/c.txt": {Data: []byte{}},
+		"empty":         {Mode: fs.ModeDir | 0755},
+	}
+	n, err := gonode.FromFS(mfs, ".")
+	if err != nil {
+		t.Fatalf("FromFS %v", err)
+	}
+	if !n.HasTag("root") || n.Len() != 3 {
+		t.Fatalf("Expected 3 children, got %d", n.Len())
+	}
+	a, ok := n.ChildByTag("a.txt").Data().(gonode.FileMeta)
+	if !ok || a.Name != "a.txt" || a.Size != 5 || a.Mode != 0644 || !a.ModTime.Equal(when) {
+		t.Errorf("Expected the metadata of a.txt, got %#v", n.ChildByTag("a.txt").Data())
+	}
+	b := n.ChildByTagDeep("b.txt")
+	if b == nil || b.Parent() != n.ChildByTag("dir") || b.Data().(gonode.FileMeta).Size != 6 {
+		t.Errorf("Expected b.txt below dir")
+	}
+	if n.ChildByTagDeep("c.txt").Depth() != 3 {
+		t.Errorf("Expected c.txt below dir/sub")
+	}
+	if meta := n.ChildByTag("empty").Data().(gonode.FileMeta); !meta.Mode.IsDir() {
+		t.Errorf("Expected empty to be a directory")
+	}
+
+	sub, err := gonode.FromFS(mfs, "dir")
+	if err != nil || sub.Len() != 2 {
+		t.Errorf("Expected dir's 2 entries, got %v", err)
+	}
+	if _, err := gonode.FromFS(mfs, "nope"); err == nil {
+		t.Errorf("Expected error for a missing directory")
+	}
+
+	// Served back, the layout is the same (but without what's in the files)
+	err = fstest.TestFS(n.FS(), "a.txt", "dir/b.txt", "dir/sub/c.txt", "empty")
+	if err != nil {
+		t.Errorf("TestFS %v", err)
+	}
+}
+
+func TestNodeFS(t *testing.T) {
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags("<h1>{{.}}</h1>", "index.tmpl")
+	n.NewChildWithDataAndTags([]byte("body{}"), "style.css")
+	conf := n.NewChildWithTags("conf")
+	conf.NewChildWithDataAndTags(map[string]any{"port": 80.0}, "web.json")
+	conf.NewChildWithData("untagged")
+	conf.NewChildWithDataAndTags("shadowed", "web.json")
+
+	fsys := n.FS()
+	err := fstest.TestFS(fsys, "index.tmpl", "style.css", "conf/web.json")
+	if err != nil {
+		t.Errorf("TestFS %v", err)
+	}
+	pay, err := fs.ReadFile(fsys, "conf/web.json")
+	if err != nil || string(pay) != `{"port":80}` {
+		t.Errorf("Expected data as json, got %q %v", pay, err)
+	}
+	entries, err := fs.ReadDir(fsys, "conf")
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected only the first web.json, got %v %v", entries, err)
+	}
+	if _, err := fs.Stat(fsys, "style.css/nope"); err == nil {
+		t.Errorf("Expected error for a path below a file")
+	}
+	info, err := fs.Stat(fsys, "style.css")
+	if err != nil || info.Sys() != n.ChildByTag("style.css") {
+		t.Errorf("Expected Sys to be the Node")
+	}
+
+	tmpl, err := template.ParseFS(fsys, "*.tmpl")
+	if err != nil {
+		t.Fatalf("ParseFS %v", err)
+	}
+	out := &strings.Builder{}
+	tmpl.Execute(out, "hi")
+	if out.String() != "<h1>hi</h1>" {
+		t.Errorf("Expected template output, got %q", out.String())
+	}
+
+	rec := httptest.NewRecorder()
+	http.FileServer(http.FS(fsys)).ServeHTTP(rec, httptest.NewRequest("GET", "/style.css", nil))
+	if rec.Code != 200 || rec.Body.String() != "body{}" {
+		t.Errorf("Expected style.css served, got %d %q", rec.Code, rec.Body.String())
+	}
+}
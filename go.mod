@@ -3,3 +3,16 @@ module github.com/beanzilla/gonode
 go 1.19
 
 require golang.org/x/exp v0.0.0-20221012211006-4de253d81b95
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/fxamacker/cbor/v2 v2.5.0
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+)
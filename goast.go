@@ -0,0 +1,106 @@
+package gonode
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+)
+
+// The attributes FromGoAST gives each Node
+const (
+	GoPosAttr   = "go:pos"   // Where the syntax starts (i.e. "main.go:3:1")
+	GoEndAttr   = "go:end"   // Where the syntax ends (just after it)
+	GoFieldAttr = "go:field" // The field of the parent it's in (i.e. "Body" or "Decls")
+	GoTokenAttr = "go:tok"   // The token of operators, literals and import, const, type or var declarations (i.e. "+", "INT" or "var")
+)
+
+var (
+	astNodeType  = reflect.TypeOf((*ast.Node)(nil)).Elem()
+	astTokenType = reflect.TypeOf(token.ILLEGAL)
+)
+
+// Makes a new "root" Node from the Go source of a file, see FromGoAST
+//
+// src can be nil (to read the file), a string, []byte or io.Reader (see go/parser.ParseFile). Comments are kept
+func FromGoSource(filename string, src any) (*Node, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	return FromGoAST(fset, file), nil
+}
+
+// Makes a new "root" Node from a go/ast node (and everything below it)
+//
+// Each syntax node is tagged by it's kind (i.e. "FuncDecl", "Ident"), with where it is and the field it's in as attributes
+// (see GoPosAttr). Identifiers have their name as data, literals their value (as written) and comments their text.
+//
+// Doc comments are kept with what they document, the list of every comment of a file (and it's Imports, Scope
+// and Unresolved) are left out as they repeat what's already in the tree. A nil node gives an empty "root" Node
+func FromGoAST(fset *token.FileSet, node ast.Node) *Node {
+	n := NewNode()
+	n.fromGoAST(fset, node)
+	return n
+}
+
+// Secret util for filling this Node from a go/ast node
+func (n *Node) fromGoAST(fset *token.FileSet, node ast.Node) {
+	rv := reflect.ValueOf(node)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return
+	}
+	n.AddTag(rv.Type().Name())
+	if pos := node.Pos(); pos.IsValid() && fset != nil {
+		n.SetAttr(GoPosAttr, fset.Position(pos).String())
+	}
+	if end := node.End(); end.IsValid() && fset != nil {
+		n.SetAttr(GoEndAttr, fset.Position(end).String())
+	}
+	switch d := node.(type) {
+	case *ast.Ident:
+		n.data = d.Name
+	case *ast.BasicLit:
+		n.data = d.Value
+	case *ast.Comment:
+		n.data = d.Text
+	}
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+	_, isFile := node.(*ast.File)
+	for idx := 0; idx < rv.NumField(); idx += 1 {
+		f := rv.Type().Field(idx)
+		if isFile && (f.Name == "Comments" || f.Name == "Imports" || f.Name == "Unresolved") {
+			continue
+		}
+		fv := rv.Field(idx)
+		switch {
+		case f.Type == astTokenType:
+			n.SetAttr(GoTokenAttr, token.Token(fv.Int()).String())
+		case f.Type.Implements(astNodeType):
+			n.goASTChild(fset, fv, f.Name)
+		case f.Type.Kind() == reflect.Slice && f.Type.Elem().Implements(astNodeType):
+			for i := 0; i < fv.Len(); i += 1 {
+				n.goASTChild(fset, fv.Index(i), f.Name)
+			}
+		}
+	}
+}
+
+// Secret util for adding a child for the go/ast node in the given field (unless it's nil)
+func (n *Node) goASTChild(fset *token.FileSet, fv reflect.Value, field string) {
+	if (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface) && fv.IsNil() {
+		return
+	}
+	kid := n.NewChild()
+	kid.SetAttr(GoFieldAttr, field)
+	kid.fromGoAST(fset, fv.Interface().(ast.Node))
+}
@@ -0,0 +1,106 @@
+package gonode_test
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+const goSample = `package sample
+
+import "fmt"
+
+// Add adds
+func Add(a, b int) int {
+	return a + b // sum
+}
+
+func main() {
+	fmt.Println(Add(1, 2), "three")
+}
+`
+
+func TestFromGoSource(t *testing.T) {
+	n, err := gonode.FromGoSource("sample.go", goSample)
+	if err != nil {
+		t.Fatalf("FromGoSource %v", err)
+	}
+	if !n.HasTag("root", "File") || !n.HasAttr(gonode.GoPosAttr, "sample.go:1:1") {
+		t.Errorf("Expected the File at the top, got %v %v", n.Tags(), n.Attrs())
+	}
+	if pkg := n.ChildByAttr(gonode.GoFieldAttr, "Name"); pkg == nil || pkg.Data() != "sample" {
+		t.Errorf("Expected the package name")
+	}
+
+	add := n.ChildByTag("FuncDecl")
+	if add == nil || !add.HasAttr(gonode.GoPosAttr, "sample.go:6:1") || !add.HasAttr(gonode.GoEndAttr, "sample.go:8:2") {
+		t.Fatalf("Expected Add with it's position")
+	}
+	if add.ChildByAttr(gonode.GoFieldAttr, "Name").Data() != "Add" {
+		t.Errorf("Expected Add's name")
+	}
+	doc := add.ChildByAttr(gonode.GoFieldAttr, "Doc")
+	if doc == nil || doc.Child(0).Data() != "// Add adds" {
+		t.Errorf("Expected Add's doc comment")
+	}
+	bin := add.ChildByTagDeep("BinaryExpr")
+	if bin == nil || !bin.HasAttr(gonode.GoTokenAttr, "+") || bin.Len() != 2 {
+		t.Errorf("Expected a + b")
+	}
+
+	// Literals and where they are
+	lits := []string{}
+	var walk func(o *gonode.Node)
+	walk = func(o *gonode.Node) {
+		if o.HasTag("BasicLit") {
+			lits = append(lits, o.Data().(string))
+		}
+		for kid := range o.Iter() {
+			walk(kid)
+		}
+	}
+	walk(n)
+	want := []string{`"fmt"`, "1", "2", `"three"`}
+	if len(lits) != len(want) {
+		t.Fatalf("Expected literals %v, got %v", want, lits)
+	}
+	for idx := range want {
+		if lits[idx] != want[idx] {
+			t.Errorf("Expected literals %v, got %v", want, lits)
+		}
+	}
+	imp := n.ChildByTag("GenDecl")
+	if !imp.HasAttr(gonode.GoTokenAttr, "import") {
+		t.Errorf("Expected the import declaration")
+	}
+
+	if _, err := gonode.FromGoSource("bad.go", "package"); err == nil {
+		t.Errorf("Expected error for bad source")
+	}
+}
+
+func TestFromGoAST(t *testing.T) {
+	expr := &ast.BinaryExpr{X: &ast.Ident{Name: "x"}, Op: token.MUL, Y: &ast.BasicLit{Kind: token.INT, Value: "2"}}
+	n := gonode.FromGoAST(token.NewFileSet(), expr)
+	if !n.HasTag("BinaryExpr") || !n.HasAttr(gonode.GoTokenAttr, "*") || n.Len() != 2 {
+		t.Fatalf("Expected x * 2")
+	}
+	if _, ok := n.Attr(gonode.GoPosAttr); ok {
+		t.Errorf("Expected no position for made up syntax")
+	}
+	if !n.Child(1).HasAttr(gonode.GoTokenAttr, "INT") || !n.Child(1).HasAttr(gonode.GoFieldAttr, "Y") {
+		t.Errorf("Expected the literal's kind and field")
+	}
+}
+
+func TestFromGoASTNil(t *testing.T) {
+	var ident *ast.Ident
+	for _, node := range []ast.Node{nil, ident} {
+		n := gonode.FromGoAST(nil, node)
+		if !n.IsRoot() || n.Len() != 0 || len(n.Tags()) != 1 {
+			t.Errorf("Expected an empty root Node for %#v, got %v", node, n.Tags())
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package gonode
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// Secret envelope for gob, like envelope but with data wrapped so registered types need no gob.Register
+type gobEnvelope struct {
+	Tags     []string
+	Attrs    map[string]string
+	Data     *gobValue
+	Children []*gobEnvelope
+}
+
+// Kinds of gobValue
+const (
+	gobTyped = iota + 1 // A registered type (see RegisterType), gob encoded by itself
+	gobMap              // A map[string]any
+	gobList             // A []any
+	gobOther            // Anything else, gob needs it registered (see gob.Register)
+)
+
+// Secret wrapper for a value in gob
+type gobValue struct {
+	Kind  int
+	Type  string // Registered type name, for gobTyped
+	Value []byte // Gob of the value, for gobTyped
+	Map   map[string]*gobValue
+	List  []*gobValue
+	Other any
+}
+
+// Secret util for wrapping a value for gob, nil stays nil
+func newGobValue(v any) (*gobValue, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		g := &gobValue{Kind: gobMap, Map: map[string]*gobValue{}}
+		for k, item := range val {
+			w, err := newGobValue(item)
+			if err != nil {
+				return nil, err
+			}
+			g.Map[k] = w
+		}
+		return g, nil
+	case []any:
+		g := &gobValue{Kind: gobList, List: []*gobValue{}}
+		for _, item := range val {
+			w, err := newGobValue(item)
+			if err != nil {
+				return nil, err
+			}
+			g.List = append(g.List, w)
+		}
+		return g, nil
+	}
+	if name := typeName(v); name != "" {
+		buf := &bytes.Buffer{}
+		err := gob.NewEncoder(buf).Encode(v)
+		if err != nil {
+			return nil, err
+		}
+		return &gobValue{Kind: gobTyped, Type: name, Value: buf.Bytes()}, nil
+	}
+	return &gobValue{Kind: gobOther, Other: v}, nil
+}
+
+// Secret util for unwrapping a value from gob
+func (g *gobValue) value() (any, error) {
+	if g == nil {
+		return nil, nil
+	}
+	switch g.Kind {
+	case gobTyped:
+		ptr, err := newData(g.Type)
+		if err != nil {
+			return nil, err
+		}
+		err = gob.NewDecoder(bytes.NewReader(g.Value)).Decode(ptr.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	case gobMap:
+		m := map[string]any{}
+		for k, w := range g.Map {
+			item, err := w.value()
+			if err != nil {
+				return nil, err
+			}
+			m[k] = item
+		}
+		return m, nil
+	case gobList:
+		l := make([]any, 0, len(g.List))
+		for _, w := range g.List {
+			item, err := w.value()
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, item)
+		}
+		return l, nil
+	case gobOther:
+		return g.Other, nil
+	}
+	return nil, fmt.Errorf("unknown gob value kind %d", g.Kind)
+}
+
+// Secret util for making the gob envelope of this Node (and it's children)
+func (n *Node) gobEnvelope() (*gobEnvelope, error) {
+	data, err := newGobValue(n.data)
+	if err != nil {
+		return nil, err
+	}
+	e := &gobEnvelope{
+		Tags:  n.tags,
+		Attrs: n.attrs,
+		Data:  data,
+	}
+	for _, kid := range n.children {
+		k, err := kid.gobEnvelope()
+		if err != nil {
+			return nil, err
+		}
+		e.Children = append(e.Children, k)
+	}
+	return e, nil
+}
+
+// Secret util for turning a gob envelope back into an envelope
+func (g *gobEnvelope) envelope() (*envelope, error) {
+	data, err := g.Data.value()
+	if err != nil {
+		return nil, err
+	}
+	e := &envelope{
+		Tags:  g.Tags,
+		Attrs: g.Attrs,
+		Data:  data,
+	}
+	for _, kid := range g.Children {
+		if kid == nil {
+			e.Children = append(e.Children, nil)
+			continue
+		}
+		k, err := kid.envelope()
+		if err != nil {
+			return nil, err
+		}
+		e.Children = append(e.Children, k)
+	}
+	return e, nil
+}
+
+// Secret util for gob.RegisterName, returning an error rather than panicking when gob already has it otherwise
+func gobRegisterName(name string, sample any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("gob: %v", r)
+		}
+	}()
+	gob.RegisterName(name, sample)
+	return nil
+}
+
+// Custom Marshaler for gob (i.e. net/rpc and gob based caches)
+//
+// Data of registered types (see RegisterType), and maps and slices of them, is kept as is.
+// Data of other types must be registered with gob.Register first (as gob requires for any interface value)
+func (n *Node) GobEncode() ([]byte, error) {
+	e, err := n.gobEnvelope()
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	err = gob.NewEncoder(buf).Encode(e)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Custom Unmarshaler for gob, this Node's content is replaced (parents are linked up again)
+func (n *Node) GobDecode(data []byte) error {
+	g := &gobEnvelope{}
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(g)
+	if err != nil {
+		return err
+	}
+	e, err := g.envelope()
+	if err != nil {
+		return err
+	}
+	o, err := e.node("")
+	if err != nil {
+		return err
+	}
+	n.Merge(o, ModeReplace)
+	return nil
+}
@@ -0,0 +1,107 @@
+package gonode_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/beanzilla/gonode"
+)
+
+type gobPoint struct {
+	X, Y int
+}
+
+func TestGob(t *testing.T) {
+	gob.Register(gobPoint{})
+	when := time.Date(2022, 10, 12, 21, 10, 6, 0, time.UTC)
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags(42, "int")
+	n.NewChildWithDataAndTags(0, "zero")
+	n.NewChildWithDataAndTags(when, "time")
+	n.NewChildWithDataAndTags(gobPoint{1, 2}, "point")
+	n.NewChildWithDataAndTags(map[string]any{"k": []any{"v", 1.5}}, "json")
+	lvl := n.NewChildWithTags("level 2")
+	lvl.SetAttr("unit", "celsius")
+	lvl.NewChildWithTags("deep").NewChild()
+
+	// Travels inside other values, like with net/rpc
+	type message struct {
+		Name string
+		Tree *gonode.Node
+	}
+	buf := &bytes.Buffer{}
+	err := gob.NewEncoder(buf).Encode(message{"tree", n})
+	if err != nil {
+		t.Fatalf("gob Encode %v", err)
+	}
+	got := message{}
+	err = gob.NewDecoder(buf).Decode(&got)
+	if err != nil {
+		t.Fatalf("gob Decode %v", err)
+	}
+	dummy := got.Tree
+	if dummy == nil || !dummy.HasTag("root") || dummy.Len() != n.Len() {
+		t.Fatalf("Expected the tree back")
+	}
+	for idx, want := range []any{42, 0, when, gobPoint{1, 2}, map[string]any{"k": []any{"v", 1.5}}} {
+		if !reflect.DeepEqual(dummy.Child(idx).Data(), want) {
+			t.Errorf("Expected %#v, got %#v", want, dummy.Child(idx).Data())
+		}
+	}
+	deep := dummy.ChildByTagDeep("deep")
+	if deep == nil || deep.Parent().Parent() != dummy || deep.Child(0).Parent() != deep {
+		t.Errorf("Expected parent links back")
+	}
+	if !dummy.ChildByTag("level 2").HasAttr("unit", "celsius") {
+		t.Errorf("Expected attributes back")
+	}
+
+	type unknown struct{ A int }
+	_, err = gonode.NewNodeWithData(unknown{1}).GobEncode()
+	if err == nil {
+		t.Errorf("Expected error for data of an unregistered type")
+	}
+}
+
+type gobTemp struct {
+	Celsius float64
+}
+
+type gobOwnName struct {
+	A int
+}
+
+func TestGobRegisterType(t *testing.T) {
+	// Registered types go through gob without gob.Register
+	err := gonode.RegisterType("gob.temp", gobTemp{})
+	if err != nil {
+		t.Fatalf("RegisterType %v", err)
+	}
+	n := gonode.NewNode()
+	n.NewChildWithData(gobTemp{21.5})
+	n.NewChildWithData(map[string]any{"list": []any{gobTemp{-3}, int64(7)}})
+	pay, err := n.GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode %v", err)
+	}
+	dummy := gonode.NewNode()
+	err = dummy.GobDecode(pay)
+	if err != nil {
+		t.Fatalf("GobDecode %v", err)
+	}
+	if got := dummy.Child(0).Data(); got != (gobTemp{21.5}) {
+		t.Errorf("Expected gobTemp back, got %#v", got)
+	}
+	if got, want := dummy.Child(1).Data(), (map[string]any{"list": []any{gobTemp{-3}, int64(7)}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %#v, got %#v", want, got)
+	}
+
+	// Already known to gob by another name is an error, not a panic
+	gob.RegisterName("mine.OwnName", gobOwnName{})
+	if err := gonode.RegisterType("gob.ownName", gobOwnName{}); err == nil {
+		t.Errorf("Expected error for a type gob knows by another name")
+	}
+}
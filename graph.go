@@ -0,0 +1,167 @@
+package gonode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// What the diagrams of WriteDOT and WriteMermaid show for each Node
+type GraphLabel int
+
+const (
+	LabelTags GraphLabel = iota // Tags (joined by commas)
+	LabelData                   // Data (formatted with fmt.Sprint)
+	LabelBoth                   // Tags with data below them
+)
+
+// Options for WriteDOT and WriteMermaid, the zero value shows every Node by it's tags
+type GraphOptions struct {
+	Label GraphLabel
+	// Extra styling for Nodes with a tag (the first tag of a Node with a style is used),
+	// for DOT these are node attributes (i.e. `color=red, shape=box`), for Mermaid a classDef (i.e. `fill:#f96,stroke:#333`)
+	Styles      map[string]string
+	MaxDepth    int // How many levels below the Node to show, 0 (or less) is no limit
+	MaxChildren int // How many children of each Node to show, 0 (or less) is no limit
+	MaxData     int // How many characters of data to show, 0 (or less) is no limit
+}
+
+// Secret Node of a diagram, more is set for the Node standing in for what was left out
+type graphNode struct {
+	id    string
+	lines []string
+	style string // The tag it's styled by
+	more  bool
+}
+
+// Secret edge of a diagram
+type graphEdge struct {
+	from, to string
+}
+
+// Secret util for the Nodes and edges of the diagram of this Node (and everything below it)
+func (n *Node) graph(opts GraphOptions) ([]graphNode, []graphEdge) {
+	nodes := []graphNode{}
+	edges := []graphEdge{}
+	var walk func(o *Node, depth int)
+	walk = func(o *Node, depth int) {
+		id := "n" + strconv.Itoa(len(nodes))
+		gn := graphNode{id: id, lines: o.graphLines(opts.Label, opts.MaxData)}
+		for _, tag := range o.tags {
+			if _, ok := opts.Styles[tag]; ok {
+				gn.style = tag
+				break
+			}
+		}
+		nodes = append(nodes, gn)
+		more := func(what string) {
+			mid := "n" + strconv.Itoa(len(nodes))
+			nodes = append(nodes, graphNode{id: mid, lines: []string{what}, more: true})
+			edges = append(edges, graphEdge{id, mid})
+		}
+		if o.Len() == 0 {
+			return
+		}
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			more("... " + plural(o.Len(), "child", "children"))
+			return
+		}
+		shown := o.children
+		if opts.MaxChildren > 0 && len(shown) > opts.MaxChildren {
+			shown = shown[:opts.MaxChildren]
+		}
+		for _, kid := range shown {
+			edges = append(edges, graphEdge{id, "n" + strconv.Itoa(len(nodes))}) // The id walk gives it
+			walk(kid, depth+1)
+		}
+		if left := o.Len() - len(shown); left > 0 {
+			more("... " + strconv.Itoa(left) + " more")
+		}
+	}
+	walk(n, 0)
+	return nodes, edges
+}
+
+// Secret util for the lines of text labelling this Node in a diagram
+func (n *Node) graphLines(label GraphLabel, maxData int) []string {
+	lines := []string{}
+	if label != LabelData && len(n.tags) != 0 {
+		lines = append(lines, strings.Join(n.tags, ", "))
+	}
+	if label != LabelTags && n.data != nil {
+		lines = append(lines, dataText(n.data, maxData, "..."))
+	}
+	return lines
+}
+
+// Writes this Node (and everything below it) as a Graphviz DOT digraph
+func (n *Node) WriteDOT(w io.Writer, opts GraphOptions) error {
+	nodes, edges := n.graph(opts)
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph tree {\n")
+	for _, gn := range nodes {
+		lines := make([]string, len(gn.lines))
+		for idx, line := range gn.lines {
+			lines[idx] = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(line)
+		}
+		fmt.Fprintf(bw, "  %s [label=\"%s\"", gn.id, strings.Join(lines, `\n`))
+		if gn.more {
+			bw.WriteString(", style=dashed")
+		}
+		if gn.style != "" {
+			bw.WriteString(", " + opts.Styles[gn.style])
+		}
+		bw.WriteString("];\n")
+	}
+	for _, e := range edges {
+		fmt.Fprintf(bw, "  %s -> %s;\n", e.from, e.to)
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// Writes this Node (and everything below it) as a Mermaid flowchart (top down)
+func (n *Node) WriteMermaid(w io.Writer, opts GraphOptions) error {
+	nodes, edges := n.graph(opts)
+	bw := bufio.NewWriter(w)
+	bw.WriteString("flowchart TD\n")
+	escape := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", " ")
+	for _, gn := range nodes {
+		lines := make([]string, len(gn.lines))
+		for idx, line := range gn.lines {
+			lines[idx] = escape.Replace(line)
+		}
+		open, end := "[", "]"
+		if gn.more {
+			open, end = "[/", "/]"
+		}
+		fmt.Fprintf(bw, "  %s%s\"%s\"%s\n", gn.id, open, strings.Join(lines, "<br/>"), end)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(bw, "  %s --> %s\n", e.from, e.to)
+	}
+	// Styles as classes, named by the order of their tag
+	tags := make([]string, 0, len(opts.Styles))
+	for tag := range opts.Styles {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for idx, tag := range tags {
+		ids := []string{}
+		for _, gn := range nodes {
+			if gn.style == tag {
+				ids = append(ids, gn.id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		class := "s" + strconv.Itoa(idx)
+		fmt.Fprintf(bw, "  classDef %s %s\n", class, opts.Styles[tag])
+		fmt.Fprintf(bw, "  class %s %s\n", strings.Join(ids, ","), class)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,103 @@
+package gonode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func graphTree() *gonode.Node {
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags(`say "hi"`, "greeting", "text")
+	list := n.NewChildWithTags("list")
+	for _, v := range []int{1, 2, 3} {
+		list.NewChildWithDataAndTags(v, "item")
+	}
+	return n
+}
+
+func TestWriteDOT(t *testing.T) {
+	buf := &strings.Builder{}
+	err := graphTree().WriteDOT(buf, gonode.GraphOptions{})
+	if err != nil {
+		t.Fatalf("WriteDOT %v", err)
+	}
+	want := `digraph tree {
+  n0 [label="root"];
+  n1 [label="greeting, text"];
+  n2 [label="list"];
+  n3 [label="item"];
+  n4 [label="item"];
+  n5 [label="item"];
+  n0 -> n1;
+  n0 -> n2;
+  n2 -> n3;
+  n2 -> n4;
+  n2 -> n5;
+}
+`
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	err = graphTree().WriteDOT(buf, gonode.GraphOptions{
+		Label:       gonode.LabelBoth,
+		Styles:      map[string]string{"item": "color=red", "text": "shape=box"},
+		MaxChildren: 2,
+	})
+	if err != nil {
+		t.Fatalf("WriteDOT %v", err)
+	}
+	want = `digraph tree {
+  n0 [label="root"];
+  n1 [label="greeting, text\nsay \"hi\"", shape=box];
+  n2 [label="list"];
+  n3 [label="item\n1", color=red];
+  n4 [label="item\n2", color=red];
+  n5 [label="... 1 more", style=dashed];
+  n0 -> n1;
+  n0 -> n2;
+  n2 -> n3;
+  n2 -> n4;
+  n2 -> n5;
+}
+`
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	buf := &strings.Builder{}
+	err := graphTree().WriteMermaid(buf, gonode.GraphOptions{
+		Label:    gonode.LabelData,
+		Styles:   map[string]string{"greeting": "fill:#f96", "nope": "fill:#000"},
+		MaxDepth: 1,
+		MaxData:  3,
+	})
+	if err != nil {
+		t.Fatalf("WriteMermaid %v", err)
+	}
+	want := `flowchart TD
+  n0[""]
+  n1["say..."]
+  n2[""]
+  n3[/"... 3 children"/]
+  n0 --> n1
+  n0 --> n2
+  n2 --> n3
+  classDef s0 fill:#f96
+  class n1 s0
+`
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	graphTree().WriteMermaid(buf, gonode.GraphOptions{Label: gonode.LabelBoth})
+	if !strings.Contains(buf.String(), `n1["greeting, text<br/>say #quot;hi#quot;"]`) {
+		t.Errorf("Expected escaped quotes, got:\n%s", buf.String())
+	}
+}
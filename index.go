@@ -0,0 +1,218 @@
+package gonode
+
+// Secret index of tag -> Nodes, shared by every Node in an indexed tree
+//
+// Only the tree's top Node owns it, all others just point at it
+type tagIndex struct {
+	tags map[string]map[*Node]struct{}
+}
+
+// Secret util for registering the given tag(s) of a Node
+func (x *tagIndex) add(n *Node, tags ...string) {
+	for _, tag := range tags {
+		set, ok := x.tags[tag]
+		if !ok {
+			set = map[*Node]struct{}{}
+			x.tags[tag] = set
+		}
+		set[n] = struct{}{}
+	}
+}
+
+// Secret util for unregistering the given tag(s) of a Node
+func (x *tagIndex) remove(n *Node, tags ...string) {
+	for _, tag := range tags {
+		set, ok := x.tags[tag]
+		if !ok {
+			continue
+		}
+		delete(set, n)
+		if len(set) == 0 {
+			delete(x.tags, tag)
+		}
+	}
+}
+
+// Secret util for adding a Node (and everything below it) to the index
+func (x *tagIndex) attach(n *Node) {
+	n.index = x
+	x.add(n, n.tags...)
+	for _, kid := range n.children {
+		x.attach(kid)
+	}
+}
+
+// Secret util for removing a Node (and everything below it) from the index it belongs to
+func unindex(n *Node) {
+	if n.index == nil {
+		return
+	}
+	n.index.remove(n, n.tags...)
+	n.index = nil
+	for _, kid := range n.children {
+		unindex(kid)
+	}
+}
+
+// Secret util to keep the index in sync when o is placed below n
+func (n *Node) indexChild(o *Node) {
+	if o.index == n.index {
+		return
+	}
+	unindex(o)
+	if n.index != nil {
+		n.index.attach(o)
+	}
+}
+
+// Secret util that returns the smallest set of Nodes known for the given tag(s)
+//
+// Returns nil if any of the tag(s) is not known at all
+func (x *tagIndex) candidates(tags ...string) map[*Node]struct{} {
+	var best map[*Node]struct{}
+	for _, tag := range tags {
+		set, ok := x.tags[tag]
+		if !ok {
+			return nil
+		}
+		if best == nil || len(set) < len(best) {
+			best = set
+		}
+	}
+	return best
+}
+
+// Secret util that returns the chain of Nodes from the top Node down to (and including) this Node
+func (n *Node) lineage() []*Node {
+	line := []*Node{}
+	for at := n; at != nil; at = at.Parent() {
+		line = append(line, at)
+	}
+	for i, j := 0, len(line)-1; i < j; i, j = i+1, j-1 {
+		line[i], line[j] = line[j], line[i]
+	}
+	return line
+}
+
+// Secret util that reports if a comes before b when walking the tree depth first
+func preorderBefore(a, b *Node) bool {
+	la, lb := a.lineage(), b.lineage()
+	i := 0
+	for i < len(la) && i < len(lb) && la[i] == lb[i] {
+		i += 1
+	}
+	if i == len(la) {
+		return true // a is above b
+	}
+	if i == len(lb) || i == 0 {
+		return false
+	}
+	for _, kid := range la[i-1].children {
+		if kid == la[i] {
+			return true
+		}
+		if kid == lb[i] {
+			return false
+		}
+	}
+	return false
+}
+
+// Secret util that reports if this Node is somewhere below the given Node
+func (n *Node) isBelow(o *Node) bool {
+	for at := n.Parent(); at != nil; at = at.Parent() {
+		if at == o {
+			return true
+		}
+	}
+	return false
+}
+
+// Enables the tag index for the whole tree this Node is part of
+//
+// The index is kept on the top Node and is updated by AddTag, RmTag, AddChild, RmChild, Detach (and friends),
+// making HasTag, ChildByTag and ChildByTagDeep near-constant time on large trees
+func (n *Node) EnableTagIndex() {
+	top := n
+	for top.Parent() != nil {
+		top = top.Parent()
+	}
+	if top.index != nil {
+		return
+	}
+	x := &tagIndex{
+		tags: map[string]map[*Node]struct{}{},
+	}
+	x.attach(top)
+}
+
+// Disables the tag index for the whole tree this Node is part of
+func (n *Node) DisableTagIndex() {
+	top := n
+	for top.Parent() != nil {
+		top = top.Parent()
+	}
+	unindex(top)
+}
+
+// Checks if the tree this Node is part of has the tag index enabled
+func (n *Node) TagIndexed() bool {
+	return n.index != nil
+}
+
+// Secret util for ChildByTag when the tag index is enabled
+func (n *Node) indexedChildByTag(tags ...string) *Node {
+	set := n.index.candidates(tags...)
+	if len(set) > n.Len() {
+		// Cheaper to just look over our own children
+		for _, kid := range n.children {
+			if kid.HasTag(tags...) {
+				return kid
+			}
+		}
+		return nil
+	}
+	found := []*Node{}
+	for kid := range set {
+		if kid.Parent() == n && kid.HasTag(tags...) {
+			found = append(found, kid)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil
+	case 1:
+		return found[0]
+	}
+	for _, kid := range n.children {
+		for _, f := range found {
+			if kid == f {
+				return kid
+			}
+		}
+	}
+	return nil
+}
+
+// Past this many candidates ordering them costs more than just walking the tree
+const indexDeepLimit = 32
+
+// Secret util for ChildByTagDeep when the tag index is enabled
+//
+// Tags shared by many Nodes fall back to walking the tree, as finding the first match by lineage is O(k*depth)
+func (n *Node) indexedChildByTagDeep(tags ...string) *Node {
+	set := n.index.candidates(tags...)
+	if len(set) > indexDeepLimit {
+		return n.scanChildByTagDeep(tags...)
+	}
+	var first *Node = nil
+	for kid := range set {
+		if !kid.isBelow(n) || !kid.HasTag(tags...) {
+			continue
+		}
+		if first == nil || preorderBefore(kid, first) {
+			first = kid
+		}
+	}
+	return first
+}
@@ -0,0 +1,157 @@
+package gonode_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestTagIndex(t *testing.T) {
+	n := gonode.NewNode()
+	a := n.NewChildWithTags("kid 1")
+	b := n.NewChildWithTags("kid 2", "shared")
+	c := a.NewChildWithTags("kid 1.1", "shared")
+	n.EnableTagIndex()
+	if !a.TagIndexed() || !c.TagIndexed() {
+		t.Errorf("Expected every Node in the tree to be indexed")
+	}
+
+	if kid := n.ChildByTagDeep("shared"); kid != c {
+		t.Errorf("Expected 'kid 1.1' as first deep match for 'shared', got '%s'", kid.Tags())
+	}
+	if kid := n.ChildByTag("shared"); kid != b {
+		t.Errorf("Expected 'kid 2' as first direct match for 'shared'")
+	}
+	if kid := a.ChildByTagDeep("kid 2"); kid != nil {
+		t.Errorf("Expected nil, 'kid 2' isn't below 'kid 1'")
+	}
+
+	// Kept up to date by tag changes
+	c.RmTag("shared")
+	if c.HasTag("shared") {
+		t.Errorf("Expected 'shared' removed, got '%s'", c.Tags())
+	}
+	if kid := n.ChildByTagDeep("shared"); kid != b {
+		t.Errorf("Expected 'kid 2' after removing tag from 'kid 1.1'")
+	}
+	a.AddTag("shared")
+	if kid := n.ChildByTagDeep("shared"); kid != a {
+		t.Errorf("Expected 'kid 1' after adding tag")
+	}
+
+	// Kept up to date by hierarchy changes
+	d := gonode.NewNodeWithTags("kid 3")
+	n.AddChild(d)
+	if !d.TagIndexed() || n.ChildByTag("kid 3") != d {
+		t.Errorf("Expected added child to be indexed")
+	}
+	if !a.Detach() {
+		t.Errorf("Expected successful Detach")
+	}
+	if a.TagIndexed() || c.TagIndexed() {
+		t.Errorf("Expected detached Nodes to no longer be indexed")
+	}
+	if kid := n.ChildByTagDeep("kid 1.1"); kid != nil {
+		t.Errorf("Expected nil, 'kid 1.1' was detached")
+	}
+	n.RmChild(0)
+	if kid := n.ChildByTagDeep("shared"); kid != nil {
+		t.Errorf("Expected nil, 'kid 2' was removed")
+	}
+	e := n.IndexNewChildWithTags(-1, "kid 0")
+	if n.ChildByTag("kid 0") != e {
+		t.Errorf("Expected IndexNewChildWithTags to be indexed")
+	}
+
+	// Tags shared by many Nodes still find the first one depth first
+	for i := 0; i < 50; i += 1 {
+		n.NewChildWithTags("many").NewChildWithTags("many")
+	}
+	f := e.NewChildWithTags("many")
+	if kid := n.ChildByTagDeep("many"); kid != f {
+		t.Errorf("Expected the 'many' below 'kid 0' as first deep match")
+	}
+
+	n.DisableTagIndex()
+	if n.TagIndexed() || e.TagIndexed() {
+		t.Errorf("Expected index disabled")
+	}
+	if n.ChildByTag("kid 0") != e || !e.HasTag("kid 0") {
+		t.Errorf("Expected lookups to keep working without the index")
+	}
+}
+
+// Secret util to build a tree of roughly 100k Nodes, each with an unique tag
+func benchTree(b *testing.B) (*gonode.Node, string) {
+	root := gonode.NewNode()
+	id := 0
+	var grow func(n *gonode.Node, depth int)
+	grow = func(n *gonode.Node, depth int) {
+		if depth == 0 {
+			return
+		}
+		for i := 0; i < 10; i += 1 {
+			kid := n.NewChildWithTags(fmt.Sprintf("id %d", id), fmt.Sprintf("level %d", depth))
+			id += 1
+			grow(kid, depth-1)
+		}
+	}
+	grow(root, 5)
+	return root, fmt.Sprintf("id %d", id-1)
+}
+
+func BenchmarkChildByTagDeep(b *testing.B) {
+	root, last := benchTree(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i += 1 {
+		if root.ChildByTagDeep(last) == nil {
+			b.Fatal("expected a match")
+		}
+	}
+}
+
+func BenchmarkChildByTagDeepIndexed(b *testing.B) {
+	root, last := benchTree(b)
+	root.EnableTagIndex()
+	b.ResetTimer()
+	for i := 0; i < b.N; i += 1 {
+		if root.ChildByTagDeep(last) == nil {
+			b.Fatal("expected a match")
+		}
+	}
+}
+
+func BenchmarkChildByTagDeepIndexedCommon(b *testing.B) {
+	root, _ := benchTree(b)
+	root.EnableTagIndex()
+	b.ResetTimer()
+	for i := 0; i < b.N; i += 1 {
+		if root.ChildByTagDeep("level 1") == nil {
+			b.Fatal("expected a match")
+		}
+	}
+}
+
+func BenchmarkHasTag(b *testing.B) {
+	root, last := benchTree(b)
+	kid := root.ChildByTagDeep(last)
+	b.ResetTimer()
+	for i := 0; i < b.N; i += 1 {
+		if !kid.HasTag("level 1", last) {
+			b.Fatal("expected tags")
+		}
+	}
+}
+
+func BenchmarkHasTagIndexed(b *testing.B) {
+	root, last := benchTree(b)
+	kid := root.ChildByTagDeep(last)
+	root.EnableTagIndex()
+	b.ResetTimer()
+	for i := 0; i < b.N; i += 1 {
+		if !kid.HasTag("level 1", last) {
+			b.Fatal("expected tags")
+		}
+	}
+}
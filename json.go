@@ -0,0 +1,530 @@
+package gonode
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// An error found while decoding a Node, with where it was found
+type DecodeError struct {
+	Path   string // Where in the document (i.e. Children[3].Tags[1]), empty for the top
+	Offset int64  // Byte offset into the input
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// An error for input going over one of the Limits
+type LimitError struct {
+	Limit string // Name of the limit (i.e. "MaxDepth")
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("exceeds %s of %d", e.Limit, e.Max)
+}
+
+// Limits for decoding untrusted input with a Decoder (see SetLimits) or UnmarshalCBORLimits, a limit of 0 (or less) is no limit
+//
+// UnmarshalJSON and UnmarshalJSONMode don't check any limits, use a Decoder (and DecodeInto) for untrusted input
+type Limits struct {
+	MaxDepth     int // How deep Nodes can be nested (the top Node has a depth of 0)
+	MaxChildren  int // How many children a single Node can have
+	MaxNodes     int // How many Nodes in total
+	MaxTagLength int // How long (in bytes) a tag, attribute key, attribute value or data type name can be
+	MaxDataSize  int // How large (in bytes of json) a Node's data (or unknown key's value) can be, checked once read in
+}
+
+var (
+	errExpectedObject = errors.New("expected object")
+	errExpectedArray  = errors.New("expected array")
+	errExpectedString = errors.New("expected string")
+)
+
+// Secret util for joining paths in a DecodeError
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// Secret decoder of the json envelope ({"Data", "Tags", "Children"}) read token by token
+type jsonDecoder struct {
+	dec       *json.Decoder
+	limits    Limits
+	nodes     int
+	each      func(n *Node) error
+	eachDepth int
+	verify    ed25519.PublicKey
+}
+
+func newJSONDecoder(r io.Reader) *jsonDecoder {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &jsonDecoder{dec: dec}
+}
+
+// Secret util for making a DecodeError at the current position
+func (d *jsonDecoder) fail(path string, err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	var de *DecodeError
+	if errors.As(err, &de) {
+		return err
+	}
+	return &DecodeError{Path: path, Offset: d.dec.InputOffset(), Err: err}
+}
+
+// Secret util for reading the given delimiter
+func (d *jsonDecoder) delim(path string, want json.Delim, expected error) error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return d.fail(path, err)
+	}
+	if tok != want {
+		return d.fail(path, expected)
+	}
+	return nil
+}
+
+// Secret util for checking a value against a limit
+func (d *jsonDecoder) limit(path, name string, max, value int) error {
+	if max > 0 && value > max {
+		return d.fail(path, &LimitError{Limit: name, Max: max})
+	}
+	return nil
+}
+
+// Secret util for reading a tag (or attribute), limited by MaxTagLength
+func (d *jsonDecoder) tag(path string) (string, error) {
+	s, err := d.str(path)
+	if err != nil {
+		return "", err
+	}
+	return s, d.limit(path, "MaxTagLength", d.limits.MaxTagLength, len(s))
+}
+
+// Secret util for reading a string
+func (d *jsonDecoder) str(path string) (string, error) {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return "", d.fail(path, err)
+	}
+	s, ok := tok.(string)
+	if !ok {
+		return "", d.fail(path, errExpectedString)
+	}
+	return s, nil
+}
+
+// Secret util for decoding the whole (single) document into the given Node
+func (d *jsonDecoder) decode(o *Node) error {
+	err := d.node(o, "", 0)
+	if err != nil {
+		return err
+	}
+	if _, err := d.dec.Token(); err != io.EOF {
+		return d.fail("", errors.New("unexpected data after top-level object"))
+	}
+	return nil
+}
+
+// Secret util for filling the given Node from the next json object
+func (d *jsonDecoder) node(o *Node, path string, depth int) error {
+	d.nodes += 1
+	err := d.limit(path, "MaxNodes", d.limits.MaxNodes, d.nodes)
+	if err != nil {
+		return err
+	}
+	err = d.limit(path, "MaxDepth", d.limits.MaxDepth, depth)
+	if err != nil {
+		return err
+	}
+	err = d.delim(path, '{', errExpectedObject)
+	if err != nil {
+		return err
+	}
+	var raw json.RawMessage
+	name := ""
+	for d.dec.More() {
+		key, err := d.str(path)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "Data":
+			err = d.dec.Decode(&raw)
+			if err != nil {
+				return d.fail(joinPath(path, key), err)
+			}
+			err = d.limit(joinPath(path, key), "MaxDataSize", d.limits.MaxDataSize, len(raw))
+			if err != nil {
+				return err
+			}
+		case "Type":
+			name, err = d.tag(joinPath(path, key))
+			if err != nil {
+				return err
+			}
+		case "Tags":
+			err = d.tags(o, joinPath(path, key))
+		case "Attrs":
+			err = d.attrs(o, joinPath(path, key))
+		case "Children":
+			err = d.children(o, joinPath(path, key), depth)
+		default:
+			// Unknown keys are skipped, but still count against MaxDataSize
+			var skip json.RawMessage
+			err = d.dec.Decode(&skip)
+			if err != nil {
+				err = d.fail(joinPath(path, key), err)
+			} else {
+				err = d.limit(joinPath(path, key), "MaxDataSize", d.limits.MaxDataSize, len(skip))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	err = d.delim(path, '}', errExpectedObject)
+	if err != nil {
+		return err
+	}
+	if raw != nil {
+		data, err := decodeData(raw, name)
+		if err != nil {
+			return d.fail(joinPath(path, "Data"), err)
+		}
+		err = o.SetData(data)
+		if err != nil {
+			return d.fail(joinPath(path, "Data"), err)
+		}
+	}
+	// Nodes above the ones handed out by DecodeEach don't have all their children, so can't be checked
+	if d.verify != nil && o.signed() && (d.each == nil || depth >= d.eachDepth) {
+		err = o.Verify(d.verify)
+		if err != nil {
+			return d.fail(path, err)
+		}
+	}
+	return nil
+}
+
+// Secret util for reading the tags array
+func (d *jsonDecoder) tags(o *Node, path string) error {
+	err := d.delim(path, '[', errExpectedArray)
+	if err != nil {
+		return err
+	}
+	for idx := 0; d.dec.More(); idx += 1 {
+		tag, err := d.tag(path + "[" + strconv.Itoa(idx) + "]")
+		if err != nil {
+			return err
+		}
+		o.AddTag(tag)
+	}
+	return d.delim(path, ']', errExpectedArray)
+}
+
+// Secret util for reading the attributes object
+func (d *jsonDecoder) attrs(o *Node, path string) error {
+	err := d.delim(path, '{', errExpectedObject)
+	if err != nil {
+		return err
+	}
+	for d.dec.More() {
+		key, err := d.tag(path)
+		if err != nil {
+			return err
+		}
+		value, err := d.tag(joinPath(path, key))
+		if err != nil {
+			return err
+		}
+		o.SetAttr(key, value)
+	}
+	return d.delim(path, '}', errExpectedObject)
+}
+
+// Secret util for reading the children array
+func (d *jsonDecoder) children(o *Node, path string, depth int) error {
+	err := d.delim(path, '[', errExpectedArray)
+	if err != nil {
+		return err
+	}
+	for idx := 0; d.dec.More(); idx += 1 {
+		err = d.limit(path, "MaxChildren", d.limits.MaxChildren, idx+1)
+		if err != nil {
+			return err
+		}
+		kid := o.NewChild()
+		err = d.node(kid, path+"["+strconv.Itoa(idx)+"]", depth+1)
+		if err != nil {
+			return err
+		}
+		if d.each != nil && depth+1 == d.eachDepth {
+			if d.verify != nil && !kid.signed() {
+				return d.fail(path+"["+strconv.Itoa(idx)+"]", ErrNotSigned)
+			}
+			err = d.each(kid)
+			if err != nil {
+				return err
+			}
+			// Let go of it (unless fn moved it), the caller has it now
+			last := len(o.children) - 1
+			if last >= 0 && o.children[last] == kid {
+				o.children = o.children[:last]
+				unindex(kid)
+				kid.parent = nil
+			}
+		}
+	}
+	return d.delim(path, ']', errExpectedArray)
+}
+
+// A Decoder reads Nodes from a stream of json (using the same layout as MarshalJSON)
+//
+// Use SetLimits before decoding input that can't be trusted
+type Decoder struct {
+	d *jsonDecoder
+}
+
+// Makes a new Decoder reading from the given io.Reader
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{d: newJSONDecoder(r)}
+}
+
+// Assigns the limits checked while decoding, going over a limit returns a *DecodeError wrapping a *LimitError
+//
+// MaxDataSize is checked after the data is read, wrap the io.Reader with io.LimitReader to bound the whole input
+func (dec *Decoder) SetLimits(limits Limits) {
+	dec.d.limits = limits
+}
+
+// Checks signatures (see Sign) with the given key while decoding, nil stops checking
+//
+// Every signed Node must verify, and the Nodes handed out (by Decode or DecodeEach) must be signed,
+// otherwise a *DecodeError wrapping ErrNotSigned or ErrBadSignature is returned
+func (dec *Decoder) SetVerifyKey(pub ed25519.PublicKey) {
+	dec.d.verify = pub
+}
+
+// Decodes the next Node from the stream
+//
+// Returns io.EOF when there is nothing left to decode
+func (dec *Decoder) Decode() (*Node, error) {
+	if !dec.d.dec.More() {
+		_, err := dec.d.dec.Token()
+		if err == nil {
+			err = dec.d.fail("", errExpectedObject)
+		}
+		return nil, err
+	}
+	dec.d.nodes = 0
+	n := &Node{}
+	err := dec.d.node(n, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if dec.d.verify != nil && dec.d.each == nil && !n.signed() {
+		return nil, dec.d.fail("", ErrNotSigned)
+	}
+	n.fixLegacyRootTag()
+	return n, nil
+}
+
+// Decodes the next document, handing each Node found at the given depth (with everything below it) to fn
+//
+// Nodes are removed from their parent once fn returns, so the whole tree is never built.
+// Within fn the Node is still attached, Parent() gives the Nodes above it (with any values read so far).
+//
+// Returns io.EOF when there is nothing left to decode, or the first error returned by fn
+func (dec *Decoder) DecodeEach(depth int, fn func(n *Node) error) error {
+	if depth == 0 {
+		n, err := dec.Decode()
+		if err != nil {
+			return err
+		}
+		return fn(n)
+	}
+	dec.d.each = fn
+	dec.d.eachDepth = depth
+	defer func() {
+		dec.d.each = nil
+	}()
+	_, err := dec.Decode()
+	return err
+}
+
+// An Encoder writes Nodes as a stream of json to the io.Writer
+//
+// Uses the same layout as MarshalJSON, Children are written last so readers see a Node's own values first.
+// Each Node is built up in memory first, so a failed Encode writes nothing
+type Encoder struct {
+	w         io.Writer
+	buf       bytes.Buffer // The document being built
+	typed     bool
+	canonical bool
+	sortTags  bool
+	unsigned  *Node // Written without it's signature (for signing)
+}
+
+// Makes a new Encoder writing to the given io.Writer
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Includes the type name of the data (see MarshalTypedJSON)
+func (enc *Encoder) SetTyped(typed bool) {
+	enc.typed = typed
+}
+
+// Writes the canonical form (see MarshalCanonicalJSON), so identical trees give identical bytes
+func (enc *Encoder) SetCanonical(canonical bool) {
+	enc.canonical = canonical
+}
+
+// Writes tags sorted, for when the order tags were added in doesn't matter
+func (enc *Encoder) SetSortTags(sortTags bool) {
+	enc.sortTags = sortTags
+}
+
+// Writes the given Node (and everything below it), followed by a newline
+func (enc *Encoder) Encode(n *Node) error {
+	enc.buf.Reset()
+	err := enc.node(n)
+	if err != nil {
+		return err
+	}
+	enc.buf.WriteByte('\n')
+	_, err = enc.w.Write(enc.buf.Bytes())
+	return err
+}
+
+// Secret util for writing a single Node
+func (enc *Encoder) node(n *Node) error {
+	enc.buf.WriteByte('{')
+	first := true
+	field := func(key string) {
+		if !first {
+			enc.buf.WriteByte(',')
+		}
+		first = false
+		enc.buf.WriteString(`"` + key + `":`)
+	}
+	if len(n.tags) != 0 {
+		field("Tags")
+		tags := n.tags
+		if enc.sortTags {
+			tags = append([]string{}, n.tags...)
+			sort.Strings(tags)
+		}
+		if err := enc.value(tags); err != nil {
+			return err
+		}
+	}
+	attrs := n.attrs
+	if n == enc.unsigned && n.signed() {
+		attrs = n.Attrs()
+		delete(attrs, SignatureAttr)
+	}
+	if len(attrs) != 0 {
+		field("Attrs")
+		if err := enc.value(attrs); err != nil {
+			return err
+		}
+	}
+	if n.data != nil {
+		if name := typeName(n.data); enc.typed && name != "" {
+			field("Type")
+			if err := enc.value(name); err != nil {
+				return err
+			}
+		}
+		field("Data")
+		if err := enc.value(n.data); err != nil {
+			return err
+		}
+	}
+	if n.Len() != 0 {
+		field("Children")
+		enc.buf.WriteByte('[')
+		for idx, kid := range n.children {
+			if idx != 0 {
+				enc.buf.WriteByte(',')
+			}
+			if err := enc.node(kid); err != nil {
+				return err
+			}
+		}
+		enc.buf.WriteByte(']')
+	}
+	enc.buf.WriteByte('}')
+	return nil
+}
+
+// Secret util for writing a single json value
+func (enc *Encoder) value(v any) error {
+	marshal := json.Marshal
+	if enc.canonical {
+		marshal = canonicalJSON
+	}
+	pay, err := marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = enc.buf.Write(pay)
+	return err
+}
+
+// Secret util for encoding a Node into memory (the bytes belong to the Encoder)
+func (enc *Encoder) bytes(n *Node) ([]byte, error) {
+	enc.buf.Reset()
+	err := enc.node(n)
+	if err != nil {
+		return nil, err
+	}
+	return enc.buf.Bytes(), nil
+}
+
+// Custom Marshaler for json
+func (n *Node) MarshalJSON() ([]byte, error) {
+	return (&Encoder{}).bytes(n)
+}
+
+// Marshals this Node to json, including the type name of the data
+//
+// Data of a registered type (see RegisterType) is restored as that type by UnmarshalJSON
+// rather than the usual float64, string, map[string]any and friends.
+// Without the type name integers a float64 can't hold exactly come back as int64, uint64 or *big.Int (when wider)
+func (n *Node) MarshalTypedJSON() ([]byte, error) {
+	enc := &Encoder{}
+	enc.SetTyped(true)
+	return enc.bytes(n)
+}
+
+// Custom Unmarshaler for json
+//
+// Numbers in data are float64, except integers a float64 can't hold exactly (int64, uint64 or *big.Int when wider).
+// Malformed input returns a *DecodeError naming where the problem is (i.e. Children[3].Tags[1]: expected string).
+// No Limits are checked, use a Decoder with SetLimits for input that can't be trusted
+func (n *Node) UnmarshalJSON(data []byte) error {
+	return n.UnmarshalJSONMode(data, ModeReplace)
+}
@@ -0,0 +1,88 @@
+package gonode_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	cases := map[string]string{
+		`{"Tags": "x"}`:          `Tags: expected array`,
+		`{"Tags": ["a", 1]}`:     `Tags[1]: expected string`,
+		`{"Attrs": {"unit": 3}}`: `Attrs.unit: expected string`,
+		`{"Children": {}}`:       `Children: expected array`,
+		`{"Children": [{}, {}, {}, {"Tags": ["ok", ["no"]]}]}`: `Children[3].Tags[1]: expected string`,
+		`{"Children": [42]}`:     `Children[0]: expected object`,
+		`{"Data": 1, "Type": 2}`: `Type: expected string`,
+		`[]`:                     `expected object`,
+	}
+	for doc, want := range cases {
+		n := gonode.NewNode()
+		err := n.UnmarshalJSON([]byte(doc))
+		if err == nil {
+			t.Errorf("Expected error for %s", doc)
+			continue
+		}
+		if err.Error() != want {
+			t.Errorf("Expected '%s' for %s, got '%s'", want, doc, err)
+		}
+		var de *gonode.DecodeError
+		if !errors.As(err, &de) {
+			t.Errorf("Expected *DecodeError for %s, got %T", doc, err)
+		}
+	}
+
+	// Through json.Unmarshal too
+	n := gonode.NewNode()
+	err := json.Unmarshal([]byte(`{"Children": [{"Tags": "x"}]}`), &n)
+	if err == nil || err.Error() != "Children[0].Tags: expected array" {
+		t.Errorf("Expected positioned error, got %v", err)
+	}
+	if n.Len() != 0 || !n.HasTag("root") {
+		t.Errorf("Expected Node untouched after error")
+	}
+}
+
+func TestUnmarshalJSONParents(t *testing.T) {
+	n := gonode.NewNode()
+	err := json.Unmarshal([]byte(`{"Tags": ["root"], "Children": [{"Children": [{}]}]}`), &n)
+	if err != nil {
+		t.Fatalf("json.Unmarshal %v", err)
+	}
+	if n.Child(0).Parent() != n || n.Child(0).Child(0).Parent() != n.Child(0) {
+		t.Errorf("Expected parents to point at the Node unmarshaled into")
+	}
+}
+
+func FuzzUnmarshalJSON(f *testing.F) {
+	seeds := []string{
+		`{}`,
+		`{"Data": 9.81, "Tags": ["gravity"]}`,
+		`{"Tags": ["root"], "Attrs": {"unit": "celsius"}, "Children": [{"Data": "Hello World"}]}`,
+		`{"Data": 42, "Type": "int"}`,
+		`{"Data": "2022-10-12T21:10:06Z", "Type": "time.Time"}`,
+		`{"Tags": "x"}`,
+		`{"Children": [{"Children": [[]]}]}`,
+		`{"Attrs": {"a": null}}`,
+		`{"Data": {"nested": [1, 2, {"three": null}]}}`,
+		`{"Children": [`,
+		`null`,
+	}
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		n := gonode.NewNode()
+		err := n.UnmarshalJSON(data)
+		if err != nil {
+			return
+		}
+		_, err = json.Marshal(n)
+		if err != nil {
+			t.Errorf("json.Marshal after successful decode %v", err)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package gonode
+
+import (
+	"bytes"
+)
+
+// How content is combined with the existing content of a Node
+type MergeMode int
+
+const (
+	// Data, tags, attributes and children are all replaced
+	ModeReplace MergeMode = iota
+	// Data is replaced (when given), tags and attributes are added,
+	// children with the same tags as an existing child are merged into it, others are appended
+	ModeMerge
+	// Data is replaced (when given), tags and attributes are added, children are appended
+	ModeAppend
+)
+
+func (m MergeMode) String() string {
+	switch m {
+	case ModeReplace:
+		return "replace"
+	case ModeMerge:
+		return "merge"
+	case ModeAppend:
+		return "append"
+	}
+	return "unknown"
+}
+
+// Combines the content of the given Node into this Node using the given mode
+//
+// The given Node is detached and emptied, it's children are moved below this Node (with their parent updated)
+//
+// Returns false (changing nothing) if the given Node is this Node or above it, as that would make a cycle
+func (n *Node) Merge(o *Node, mode MergeMode) bool {
+	if o == n || n.isBelow(o) {
+		return false
+	}
+	switch mode {
+	case ModeReplace:
+		n.replaceWith(o)
+		return true
+	}
+	o.Detach()
+	if o.data != nil {
+		n.data = o.data
+	}
+	n.AddTag(o.tags...)
+	for k, v := range o.attrs {
+		n.SetAttr(k, v)
+	}
+	kids := o.children
+	o.RmAllChildren()
+	for _, kid := range kids {
+		if mode == ModeMerge && len(kid.tags) != 0 {
+			if same := n.childWithSameTags(kid); same != nil {
+				same.Merge(kid, mode)
+				continue
+			}
+		}
+		n.AddChild(kid)
+	}
+	return true
+}
+
+// Secret util for finding a child with exactly the same tags (in any order) as the given Node
+func (n *Node) childWithSameTags(o *Node) *Node {
+	for _, kid := range n.children {
+		if len(kid.tags) == len(o.tags) && kid.HasTag(o.tags...) {
+			return kid
+		}
+	}
+	return nil
+}
+
+// Secret util for taking over the data, tags, attributes and children of the given Node
+//
+// The given Node is detached first, it must not be this Node or above it
+func (n *Node) replaceWith(o *Node) {
+	o.Detach()
+	idx := n.index
+	unindex(n)
+	n.RmAllChildren()
+	n.data = o.data
+	n.tags = o.tags
+	n.attrs = o.attrs
+	n.children = o.children
+	for _, kid := range n.children {
+		kid.parent = n
+	}
+	o.children = []*Node{}
+	o.Destroy()
+	if idx != nil {
+		idx.attach(n)
+	}
+}
+
+// Decodes the next Node from the stream into the given Node, using the given mode
+//
+// Returns io.EOF when there is nothing left to decode (leaving the given Node untouched)
+func (dec *Decoder) DecodeInto(n *Node, mode MergeMode) error {
+	o, err := dec.Decode()
+	if err != nil {
+		return err
+	}
+	n.Merge(o, mode)
+	return nil
+}
+
+// Unmarshals json (from MarshalJSON) into this Node, using the given mode
+//
+// UnmarshalJSON is the same as using ModeReplace, on error this Node is left untouched.
+// Like UnmarshalJSON no Limits are checked, use DecodeInto with a Decoder (see SetLimits) for untrusted input
+func (n *Node) UnmarshalJSONMode(data []byte, mode MergeMode) error {
+	o := &Node{}
+	err := newJSONDecoder(bytes.NewReader(data)).decode(o)
+	if err != nil {
+		return err
+	}
+	o.fixLegacyRootTag()
+	n.Merge(o, mode)
+	return nil
+}
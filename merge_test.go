@@ -0,0 +1,133 @@
+package gonode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+// Secret util to check every parent pointer below the given Node
+func checkParents(t *testing.T, n *gonode.Node) {
+	for kid := range n.Iter() {
+		if kid.Parent() != n {
+			t.Errorf("Expected child '%s' to point at parent '%s'", kid.Tags(), n.Tags())
+		}
+		checkParents(t, kid)
+	}
+}
+
+func TestUnmarshalJSONMode(t *testing.T) {
+	doc := []byte(`{"Tags": ["root", "config"], "Data": "new", "Attrs": {"v": "2"}, "Children": [
+		{"Tags": ["a"], "Children": [{"Tags": ["a.2"]}]},
+		{"Tags": ["c"]}
+	]}`)
+	build := func() *gonode.Node {
+		n := gonode.NewNodeWithData("old")
+		n.SetAttr("v", "1")
+		n.SetAttr("keep", "yes")
+		a := n.NewChildWithTags("a")
+		a.NewChildWithTags("a.1")
+		n.NewChildWithTags("b")
+		return n
+	}
+
+	n := build()
+	old := n.Child(0)
+	err := n.UnmarshalJSONMode(doc, gonode.ModeReplace)
+	if err != nil {
+		t.Fatalf("ModeReplace %v", err)
+	}
+	if n.Len() != 2 || n.Data() != "new" || !n.HasTag("config") || n.ChildByTag("b") != nil {
+		t.Errorf("Expected content replaced")
+	}
+	if _, ok := n.Attr("keep"); ok {
+		t.Errorf("Expected attributes replaced")
+	}
+	if old.Parent() != nil {
+		t.Errorf("Expected old children to be detached")
+	}
+	checkParents(t, n)
+
+	n = build()
+	err = n.UnmarshalJSONMode(doc, gonode.ModeMerge)
+	if err != nil {
+		t.Fatalf("ModeMerge %v", err)
+	}
+	if n.Len() != 3 || n.Data() != "new" || !n.HasTag("root", "config") {
+		t.Errorf("Expected a, b and c (got %d children) and data replaced", n.Len())
+	}
+	if v, _ := n.Attr("v"); v != "2" {
+		t.Errorf("Expected attribute 'v' updated, got '%s'", v)
+	}
+	if _, ok := n.Attr("keep"); !ok {
+		t.Errorf("Expected attribute 'keep' kept")
+	}
+	a := n.ChildByTag("a")
+	if a.Len() != 2 || a.ChildByTag("a.1") == nil || a.ChildByTag("a.2") == nil {
+		t.Errorf("Expected 'a' merged with both a.1 and a.2")
+	}
+	checkParents(t, n)
+
+	n = build()
+	err = n.UnmarshalJSONMode(doc, gonode.ModeAppend)
+	if err != nil {
+		t.Fatalf("ModeAppend %v", err)
+	}
+	if n.Len() != 4 || n.Child(2).ChildByTag("a.2") == nil {
+		t.Errorf("Expected 4 children, with the new 'a' appended")
+	}
+	checkParents(t, n)
+
+	// Errors leave the Node alone
+	n = build()
+	err = n.UnmarshalJSONMode([]byte(`{"Children": [{"Tags": 1}]}`), gonode.ModeAppend)
+	if err == nil || n.Len() != 2 || n.Data() != "old" {
+		t.Errorf("Expected error and untouched Node")
+	}
+}
+
+func TestDecodeInto(t *testing.T) {
+	n := gonode.NewNode()
+	n.EnableTagIndex()
+	dec := gonode.NewDecoder(strings.NewReader(`{"Children": [{"Tags": ["x"]}]} {"Children": [{"Tags": ["y"]}]}`))
+	for i := 0; i < 2; i += 1 {
+		err := dec.DecodeInto(n, gonode.ModeAppend)
+		if err != nil {
+			t.Fatalf("DecodeInto %v", err)
+		}
+	}
+	if n.Len() != 2 || n.ChildByTagDeep("y") == nil || !n.ChildByTag("x").TagIndexed() {
+		t.Errorf("Expected both documents appended (and indexed)")
+	}
+	checkParents(t, n)
+}
+
+func TestMergeNodes(t *testing.T) {
+	n := gonode.NewNodeWithTags("n")
+	a := n.NewChildWithTags("a")
+	b := a.NewChildWithTags("b")
+	b.NewChildWithTags("c")
+
+	if n.Merge(n, gonode.ModeReplace) || b.Merge(a, gonode.ModeAppend) || b.Merge(n, gonode.ModeReplace) {
+		t.Errorf("Expected merging a Node into itself (or below itself) to be refused")
+	}
+	if n.Len() != 1 || a.Parent() != n || b.Parent() != a {
+		t.Errorf("Expected the tree untouched after a refused Merge")
+	}
+
+	// The merged Node leaves it's old parent
+	o := gonode.NewNodeWithTags("o")
+	x := o.NewChildWithTags("x")
+	x.NewChildWithTags("y")
+	if !a.Merge(x, gonode.ModeReplace) {
+		t.Fatalf("Expected Merge to succeed")
+	}
+	if o.Len() != 0 || x.Parent() != nil {
+		t.Errorf("Expected the merged Node detached from it's parent")
+	}
+	if !a.HasTag("x") || a.ChildByTag("y") == nil || a.Parent() != n {
+		t.Errorf("Expected 'a' to take over the content of 'x'")
+	}
+	checkParents(t, n)
+}
@@ -0,0 +1,166 @@
+package gonode
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// The attribute used to remember what kind of json value a Node came from
+//
+// Only "object" and "array" are stored, anything else is a plain value held as data
+const KindAttr = "json:kind"
+
+const (
+	kindObject = "object"
+	kindArray  = "array"
+)
+
+// Makes a new "root" Node from a json value (as given by json.Unmarshal into an any)
+//
+// Objects become children tagged by their key (sorted by key), arrays become ordered children
+// and anything else becomes data. Use ToJSONValue to get the json value back.
+//
+// Decode with json.Decoder.UseNumber for numbers to round trip without loss
+func FromJSONValue(v any) (*Node, error) {
+	n := NewNode()
+	err := n.fromValue(v, "")
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Secret util for filling this Node from a json value
+func (n *Node) fromValue(v any, path string) error {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		n.SetAttr(KindAttr, kindObject)
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			err := n.NewChildWithTags(k).fromValue(val[k], path+"/"+k)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		n.SetAttr(KindAttr, kindArray)
+		for idx, item := range val {
+			err := n.NewChild().fromValue(item, path+"/"+strconv.Itoa(idx))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// Other maps and slices (i.e. map[string]string or []map[string]any)
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		obj := map[string]any{}
+		iter := rv.MapRange()
+		for iter.Next() {
+			obj[iter.Key().String()] = iter.Value().Interface()
+		}
+		return n.fromValue(obj, path)
+	case (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8:
+		arr := make([]any, rv.Len())
+		for idx := range arr {
+			arr[idx] = rv.Index(idx).Interface()
+		}
+		return n.fromValue(arr, path)
+	}
+	err := n.SetData(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", pathOrRoot(path), err)
+	}
+	return nil
+}
+
+// Converts this Node (and it's children) into a json value
+//
+// Nodes from FromJSONValue remember if they were an object or array, others are guessed:
+// children all with an unique first tag become an object (keyed by that tag), otherwise an array.
+//
+// Returns an error when a Node has both data and children, or an object child has no tag
+func (n *Node) ToJSONValue() (any, error) {
+	return n.toValue("")
+}
+
+// Secret util for converting this Node into a json value
+func (n *Node) toValue(path string) (any, error) {
+	kind, err := n.kind(path)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case kindObject:
+		obj := make(map[string]any, n.Len())
+		for idx, kid := range n.children {
+			if len(kid.tags) == 0 {
+				return nil, fmt.Errorf("%s/%d: no tag to use as key", path, idx)
+			}
+			key := kid.tags[0]
+			if _, dup := obj[key]; dup {
+				return nil, fmt.Errorf("%s/%s: duplicate key", path, key)
+			}
+			v, err := kid.toValue(path + "/" + key)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = v
+		}
+		return obj, nil
+	case kindArray:
+		arr := make([]any, 0, n.Len())
+		for idx, kid := range n.children {
+			v, err := kid.toValue(path + "/" + strconv.Itoa(idx))
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	}
+	return n.data, nil
+}
+
+// Secret util for the kind of value this Node is ("object", "array" or "" for plain data)
+func (n *Node) kind(path string) (string, error) {
+	kind, _ := n.Attr(KindAttr)
+	if kind == "" && n.Len() != 0 {
+		if n.data != nil {
+			return "", fmt.Errorf("%s: can't have both data and children", pathOrRoot(path))
+		}
+		kind = n.guessKind()
+	}
+	return kind, nil
+}
+
+// Secret util for guessing if the children are best as an object or array
+func (n *Node) guessKind() string {
+	seen := map[string]bool{}
+	for _, kid := range n.children {
+		if len(kid.tags) == 0 || seen[kid.tags[0]] {
+			return kindArray
+		}
+		seen[kid.tags[0]] = true
+	}
+	return kindObject
+}
+
+// Secret util for naming the top of a path in errors
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
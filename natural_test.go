@@ -0,0 +1,82 @@
+package gonode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestJSONValueRoundTrip(t *testing.T) {
+	docs := []string{
+		`{"name": "gonode", "version": 1.5, "tags": ["tree", "node"], "nested": {"empty": {}, "none": [], "null": null, "big": 12345678901234567890}}`,
+		`[1, "two", [3], {"four": 4}, null, true]`,
+		`"just a string"`,
+		`null`,
+		`{}`,
+	}
+	for _, doc := range docs {
+		dec := json.NewDecoder(bytes.NewBufferString(doc))
+		dec.UseNumber()
+		var orig any
+		err := dec.Decode(&orig)
+		if err != nil {
+			t.Fatalf("Decode %v", err)
+		}
+		n, err := gonode.FromJSONValue(orig)
+		if err != nil {
+			t.Fatalf("FromJSONValue(%s) %v", doc, err)
+		}
+		back, err := n.ToJSONValue()
+		if err != nil {
+			t.Fatalf("ToJSONValue(%s) %v", doc, err)
+		}
+		if !reflect.DeepEqual(orig, back) {
+			t.Errorf("Expected %#v, got %#v", orig, back)
+		}
+	}
+}
+
+func TestFromJSONValueLayout(t *testing.T) {
+	n, err := gonode.FromJSONValue(map[string]any{
+		"unit":  "celsius",
+		"temps": []any{32.0, 100.0},
+	})
+	if err != nil {
+		t.Fatalf("FromJSONValue %v", err)
+	}
+	temps := n.ChildByTag("temps")
+	if temps == nil || temps.Len() != 2 || temps.Child(1).Data() != 100.0 {
+		t.Errorf("Expected 'temps' child with 2 ordered children")
+	}
+	if kid := n.ChildByTag("unit"); kid == nil || kid.Data() != "celsius" {
+		t.Errorf("Expected 'unit' child with 'celsius' as data")
+	}
+	if !n.HasTag("root") {
+		t.Errorf("Expected a root Node, got '%s'", n.Tags())
+	}
+}
+
+func TestToJSONValueGuess(t *testing.T) {
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags(9.81, "gravity")
+	lvl := n.NewChildWithTags("level 2")
+	lvl.NewChildWithData(1)
+	lvl.NewChildWithData(2)
+	v, err := n.ToJSONValue()
+	if err != nil {
+		t.Fatalf("ToJSONValue %v", err)
+	}
+	want := map[string]any{"gravity": 9.81, "level 2": []any{1, 2}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Expected %#v, got %#v", want, v)
+	}
+
+	lvl.SetData("both")
+	_, err = n.ToJSONValue()
+	if err == nil {
+		t.Errorf("Expected error, Node has both data and children")
+	}
+}
@@ -1,7 +1,6 @@
 package gonode
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"reflect"
@@ -14,88 +13,46 @@ import (
 // Useful for nested data
 type Node struct {
 	tags     []string
+	attrs    map[string]string
 	data     any
 	parent   *Node
 	children []*Node
+	index    *tagIndex
 }
 
-// Secret util for making a nested map of the children
-func (n *Node) tomap() map[string]any {
-	pay := map[string]any{}
-	if n.data != nil {
-		pay["Data"] = n.data
-	}
-	if len(n.tags) != 0 {
-		pay["Tags"] = n.tags
-	}
-	if n.Len() != 0 {
-		kids := []map[string]any{}
-		for _, k := range n.children {
-			kids = append(kids, k.tomap())
-		}
-		pay["Children"] = kids
-	}
-	return pay
-}
+// The tag given to every Node made by NewNode (and friends)
+//
+// This tag is only a marker, use IsRoot to check if a Node is a "root" Node.
+//
+// Set to "" to no longer tag new "root" Nodes, or change it to use a different tag
+var RootTag = "root"
 
-// Secret util for making a nested Node structure from
-func (n *Node) tonode(lvl map[string]any) error {
-	o := n.NewChild()
-	if lvl["Data"] != nil {
-		err := o.SetData(lvl["Data"])
-		if err != nil {
-			return err
-		}
-	}
-	if lvl["Tags"] != nil {
-		tgs := lvl["Tags"].([]any)
-		for _, t := range tgs {
-			o.AddTag(t.(string))
-		}
+// Secret util for the tags a new "root" Node starts with
+func rootTags() []string {
+	if RootTag == "" {
+		return []string{}
 	}
-	if lvl["Children"] != nil {
-		nxt := lvl["Children"].([]any)
-		for _, l := range nxt {
-			err := o.tonode(l.(map[string]any))
-			if err != nil {
-				return err
-			}
-		}
-	}
-	return nil
-}
-
-// Custom Marshaler for json
-func (n *Node) MarshalJSON() ([]byte, error) {
-	pay := n.tomap()
-	return json.Marshal(pay)
+	return []string{RootTag}
 }
 
-// Custom Unmarshaler for json
-func (n *Node) UnmarshalJSON(data []byte) error {
-	pay := map[string]any{}
-	err := json.Unmarshal(data, &pay)
-	if err != nil {
-		return err
-	}
-	err = n.tonode(pay)
-	if err != nil {
-		return err
+// Secret util for files made before RootTag could be changed
+//
+// Those always have "root" as a tag on the top Node, swap it for the current RootTag.
+// Signed Nodes are left alone, as their signature covers the tags
+func (n *Node) fixLegacyRootTag() {
+	if RootTag == "root" || !n.IsRoot() || !n.HasTag("root") || n.signed() {
+		return
 	}
-	if n.Child(0).Parent() != nil {
-		c := n.Child(0)
-		n.data = c.data
-		n.tags = c.tags
-		n.children = c.children
-		c.Destroy()
+	n.RmTag("root")
+	if RootTag != "" {
+		n.AddTag(RootTag)
 	}
-	return nil
 }
 
 // Makes a new "root" Node
 func NewNode() *Node {
 	return &Node{
-		tags: []string{"root"},
+		tags: rootTags(),
 	}
 }
 
@@ -104,7 +61,7 @@ func NewNode() *Node {
 // Can return nil when data is Node or *Node (which are better as children rather than data)
 func NewNodeWithData(data any) *Node {
 	n := &Node{
-		tags: []string{"root"},
+		tags: rootTags(),
 	}
 	err := n.SetData(data)
 	if err != nil {
@@ -156,6 +113,13 @@ func (n *Node) Parent() *Node {
 	return n.parent
 }
 
+// Checks if this Node is a "root" Node (it has no parent)
+//
+// A detached Node becomes a "root" Node of it's own
+func (n *Node) IsRoot() bool {
+	return n.parent == nil
+}
+
 // Obtain's the number of children below this Node
 func (n *Node) Len() int {
 	return len(n.children)
@@ -198,6 +162,7 @@ func (n *Node) Iter() <-chan *Node {
 func (n *Node) AddChild(o *Node) {
 	o.parent = n
 	n.children = append(n.children, o)
+	n.indexChild(o)
 }
 
 // Creates a new Node below this Node
@@ -287,6 +252,9 @@ func (n *Node) IndexNewChild(idx int) *Node {
 		at += 1
 	}
 	n.children = new_kids
+	if o != nil {
+		n.indexChild(o)
+	}
 	return o
 }
 
@@ -323,6 +291,9 @@ func (n *Node) IndexNewChildWithTags(idx int, tags ...string) *Node {
 		at += 1
 	}
 	n.children = new_kids
+	if o != nil {
+		n.indexChild(o)
+	}
 	return o
 }
 
@@ -367,6 +338,9 @@ func (n *Node) IndexNewChildWithData(idx int, data any) *Node {
 		at += 1
 	}
 	n.children = new_kids
+	if o != nil {
+		n.indexChild(o)
+	}
 	return o
 }
 
@@ -413,34 +387,50 @@ func (n *Node) IndexNewChildWithDataAndTags(idx int, data any, tags ...string) *
 		at += 1
 	}
 	n.children = new_kids
+	if o != nil {
+		n.indexChild(o)
+	}
 	return o
 }
 
 // Sets all values to empty
 func (n *Node) Destroy() {
+	if n.index != nil {
+		n.index.remove(n, n.tags...)
+	}
 	n.data = nil
 	n.tags = []string{}
+	n.attrs = nil
 	n.RmAllChildren()
 	n.parent = nil
 }
 
-// Returns how far deep from the parent/"root" Node this Node is
+// Makes a deep copy of this Node (and everything below it), the copy has no parent
+//
+// Data is copied as is, so maps, slices and pointers are shared with this Node
+func (n *Node) Clone() *Node {
+	o := &Node{
+		data: n.data,
+		tags: append([]string{}, n.tags...),
+	}
+	if n.attrs != nil {
+		o.attrs = n.Attrs()
+	}
+	for _, kid := range n.children {
+		o.AddChild(kid.Clone())
+	}
+	return o
+}
+
+// Returns how far deep from the "root" Node this Node is
 //
-// Use "root" to tag a Node as the "root" Node (this reduces the returned depth value to it's correct value)
+// The "root" Node has a depth of 0, it's children 1, and so on (tags don't matter)
 func (n *Node) Depth() int {
-	if n.Parent() != nil {
-		depth := 0
-		at := n
-		for at.Parent() != nil {
-			at = at.Parent()
-			depth += 1
-		}
-		if at.HasTag("root") { // Exclude "root" Nodes
-			depth -= 1
-		}
-		return depth
+	depth := 0
+	for at := n; !at.IsRoot(); at = at.Parent() {
+		depth += 1
 	}
-	return 0
+	return depth
 }
 
 // Obtains a Node below this Node
@@ -457,6 +447,9 @@ func (n *Node) Child(index int) *Node {
 //
 // Returns nil if no children match the given tag(s)
 func (n *Node) ChildByTag(tags ...string) *Node {
+	if n.index != nil && len(tags) != 0 {
+		return n.indexedChildByTag(tags...)
+	}
 	for _, kid := range n.children {
 		if kid.HasTag(tags...) {
 			return kid
@@ -477,11 +470,19 @@ func (n *Node) ChildByTag(tags ...string) *Node {
 //
 // Returns nil if no children match the given tag(s)
 func (n *Node) ChildByTagDeep(tags ...string) *Node {
+	if n.index != nil && len(tags) != 0 {
+		return n.indexedChildByTagDeep(tags...)
+	}
+	return n.scanChildByTagDeep(tags...)
+}
+
+// Secret util for ChildByTagDeep, walks the tree depth first without the tag index
+func (n *Node) scanChildByTagDeep(tags ...string) *Node {
 	for _, kid := range n.children {
 		if kid.HasTag(tags...) {
 			return kid
 		} else if kid.Len() != 0 {
-			r := kid.ChildByTagDeep(tags...)
+			r := kid.scanChildByTagDeep(tags...)
 			if r != nil {
 				return r
 			}
@@ -511,8 +512,10 @@ func (n *Node) ReplaceChild(index int, o *Node) {
 	}
 	// De-couple the old child
 	n.children[index].parent = nil
+	unindex(n.children[index])
 	n.children[index] = o // Replace with new child
 	o.parent = n          // Update new child (re-couple)
+	n.indexChild(o)
 }
 
 // Removes multiple (or single) children by index(s)
@@ -527,6 +530,7 @@ func (n *Node) RmChild(indexs ...int) {
 		} else {
 			// De-couple the child from us
 			kid.parent = nil
+			unindex(kid)
 		}
 	}
 	n.children = kids
@@ -537,6 +541,7 @@ func (n *Node) RmAllChildren() {
 	for _, kid := range n.children {
 		// De-couple the child from us
 		kid.parent = nil
+		unindex(kid)
 	}
 	n.children = []*Node{}
 }
@@ -561,6 +566,14 @@ func (n *Node) Detach() bool {
 
 // Checks if this Node has the given tag(s)
 func (n *Node) HasTag(tags ...string) bool {
+	if n.index != nil {
+		for _, tag := range tags {
+			if _, ok := n.index.tags[tag][n]; !ok {
+				return false
+			}
+		}
+		return true
+	}
 	for _, tag := range tags {
 		if !slices.Contains(n.tags, tag) {
 			return false
@@ -578,6 +591,9 @@ func (n *Node) AddTag(tags ...string) {
 		}
 	}
 	n.tags = append(n.tags, need...)
+	if n.index != nil {
+		n.index.add(n, need...)
+	}
 }
 
 // Removes the given tag(s) from this Node
@@ -588,11 +604,17 @@ func (n *Node) RmTag(tags ...string) {
 			new_tags = append(new_tags, tag)
 		}
 	}
+	if n.index != nil {
+		n.index.remove(n, tags...)
+	}
 	n.tags = new_tags
 }
 
 // Removes all tags from this Node
 func (n *Node) RmAllTags() {
+	if n.index != nil {
+		n.index.remove(n, n.tags...)
+	}
 	n.tags = []string{}
 }
 
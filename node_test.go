@@ -135,11 +135,11 @@ func TestNodeHierarchy(t *testing.T) {
 		}
 		t.Logf("Expected parent excepting child back")
 	}
-	if a.Depth() != 0 {
+	if a.Depth() != 1 {
 		if !t.Failed() {
 			t.Fail()
 		}
-		t.Logf("Expected child's depth of 0, not %d", a.Depth())
+		t.Logf("Expected child's depth of 1, not %d", a.Depth())
 	}
 	n.RmTag("root")
 	n.AddTag("main")
@@ -540,3 +540,28 @@ func TestNodeIndex(t *testing.T) {
 		t.Errorf("Expected nil as error, got %#v", k4)
 	}
 }
+
+func TestClone(t *testing.T) {
+	n := gonode.NewNode()
+	n.SetAttr("a", "1")
+	kid := n.NewChildWithDataAndTags(3.14, "pi")
+	kid.NewChildWithTags("deep")
+	c := n.Clone()
+	if c == n || c.Parent() != nil || c.Len() != 1 || !c.HasTag("root") || !c.HasAttr("a", "1") {
+		t.Fatalf("Expected a copy, got %#v", c)
+	}
+	ck := c.ChildByTag("pi")
+	if ck == kid || ck.Parent() != c || ck.Data() != 3.14 || ck.Child(0).Parent() != ck {
+		t.Errorf("Expected copied children with their parents, got %#v", ck)
+	}
+	c.AddTag("copy")
+	c.SetAttr("a", "2")
+	ck.RmAllChildren()
+	if n.HasTag("copy") || !n.HasAttr("a", "1") || kid.Len() != 1 {
+		t.Errorf("Expected changes to the copy to leave the original alone")
+	}
+	n.EnableTagIndex()
+	if n.Clone().TagIndexed() {
+		t.Errorf("Expected the copy to not share the index")
+	}
+}
@@ -0,0 +1,129 @@
+package gonode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Makes a new "root" Node from an indented outline (or markdown style list)
+//
+// Each line becomes a Node below the closest line before it that's indented less (a tab counts as 4 spaces).
+// A leading "- " or "* " bullet is dropped, "#tag" words become tags and the rest of the line is the data (as a string).
+// Write "\#" for a word that starts with "#" but isn't a tag. Blank lines are skipped
+func ParseOutline(r io.Reader) (*Node, error) {
+	n := NewNode()
+	type level struct {
+		indent int
+		n      *Node
+	}
+	stack := []level{{indent: -1, n: n}}
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		line := scan.Text()
+		text := strings.TrimLeft(line, " \t")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		indent := 0
+		for _, c := range line[:len(line)-len(text)] {
+			if c == '\t' {
+				indent += 4 - indent%4
+			} else {
+				indent += 1
+			}
+		}
+		for stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		kid := stack[len(stack)-1].n.NewChild()
+		kid.outlineLine(text)
+		stack = append(stack, level{indent: indent, n: kid})
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Secret util for filling this Node from the text of a line (without it's indentation)
+func (n *Node) outlineLine(text string) {
+	if text == "-" || text == "*" {
+		return
+	}
+	if strings.HasPrefix(text, "- ") || strings.HasPrefix(text, "* ") {
+		text = text[2:]
+	}
+	words := []string{}
+	for _, word := range strings.Fields(text) {
+		switch {
+		case len(word) > 1 && word[0] == '#':
+			n.AddTag(word[1:])
+		case strings.HasPrefix(strings.TrimLeft(word, `\`), "#") && word[0] == '\\':
+			words = append(words, word[1:])
+		default:
+			words = append(words, word)
+		}
+	}
+	if len(words) != 0 {
+		n.data = strings.Join(words, " ")
+	}
+}
+
+// Writes the children of this Node as an outline (the reverse of ParseOutline)
+//
+// Each Node is a "- " bullet indented by 2 spaces per level, with it's data and then it's tags.
+// Only what ParseOutline can give back is written, so the outline reads back the same: returns an error for data
+// that isn't a string of words separated by single spaces (other types, "", runs of spaces, tabs or line breaks)
+// and for tags with spaces (any unicode.IsSpace, as ParseOutline splits on those)
+func (n *Node) WriteOutline(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, kid := range n.children {
+		err := kid.writeOutline(bw, 0)
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Secret util for writing this Node (and it's children) as lines of an outline
+func (n *Node) writeOutline(w *bufio.Writer, depth int) error {
+	words := []string{}
+	if n.data != nil {
+		text, ok := n.data.(string)
+		if !ok {
+			return fmt.Errorf("outline can't hold data of type %T", n.data)
+		}
+		if text == "" || strings.Join(strings.Fields(text), " ") != text {
+			return fmt.Errorf("outline can't hold the data %q", text)
+		}
+		for _, word := range strings.Fields(text) {
+			if strings.HasPrefix(strings.TrimLeft(word, `\`), "#") {
+				word = `\` + word // Not a tag
+			}
+			words = append(words, word)
+		}
+	}
+	for _, tag := range n.tags {
+		if tag == "" || strings.IndexFunc(tag, unicode.IsSpace) != -1 {
+			return fmt.Errorf("outline can't hold the tag %q", tag)
+		}
+		words = append(words, "#"+tag)
+	}
+	w.WriteString(strings.Repeat("  ", depth))
+	w.WriteString("-")
+	if len(words) != 0 {
+		w.WriteString(" " + strings.Join(words, " "))
+	}
+	w.WriteByte('\n')
+	for _, kid := range n.children {
+		err := kid.writeOutline(w, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
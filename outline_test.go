@@ -0,0 +1,101 @@
+package gonode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+const outlineSample = `Groceries #todo
+  - Milk #dairy #urgent
+  - Bread
+	* Rye   and  wheat
+- Work
+    Issue \#42 is #bug
+      -
+  Fix it
+`
+
+func TestParseOutline(t *testing.T) {
+	n, err := gonode.ParseOutline(strings.NewReader(outlineSample))
+	if err != nil {
+		t.Fatalf("ParseOutline %v", err)
+	}
+	if n.Len() != 2 {
+		t.Fatalf("Expected 2 top lines, got %d", n.Len())
+	}
+	groc := n.Child(0)
+	if groc.Data() != "Groceries" || !groc.HasTag("todo") || groc.Len() != 2 {
+		t.Errorf("Expected Groceries #todo with 2 children, got %v %v %d", groc.Data(), groc.Tags(), groc.Len())
+	}
+	milk := groc.Child(0)
+	if milk.Data() != "Milk" || !milk.HasTag("dairy", "urgent") {
+		t.Errorf("Expected Milk #dairy #urgent, got %v %v", milk.Data(), milk.Tags())
+	}
+	rye := groc.Child(1).Child(0)
+	if rye == nil || rye.Data() != "Rye and wheat" {
+		t.Errorf("Expected the tab indented line below Bread")
+	}
+	work := n.Child(1)
+	if work.Data() != "Work" || work.Len() != 2 {
+		t.Fatalf("Expected Work with 2 children, got %d", work.Len())
+	}
+	issue := work.Child(0)
+	if issue.Data() != "Issue #42 is" || !issue.HasTag("bug") || issue.Len() != 1 || issue.Child(0).Data() != nil {
+		t.Errorf("Expected the escaped #42 as text, got %v %v", issue.Data(), issue.Tags())
+	}
+	if work.Child(1).Data() != "Fix it" {
+		t.Errorf("Expected Fix it below Work, got %v", work.Child(1).Data())
+	}
+}
+
+func TestWriteOutline(t *testing.T) {
+	n, err := gonode.ParseOutline(strings.NewReader(outlineSample))
+	if err != nil {
+		t.Fatalf("ParseOutline %v", err)
+	}
+	buf := &strings.Builder{}
+	err = n.WriteOutline(buf)
+	if err != nil {
+		t.Fatalf("WriteOutline %v", err)
+	}
+	want := `- Groceries #todo
+  - Milk #dairy #urgent
+  - Bread
+    - Rye and wheat
+- Work
+  - Issue \#42 is #bug
+    -
+  - Fix it
+`
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	// Round trip
+	again, err := gonode.ParseOutline(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("ParseOutline %v", err)
+	}
+	a, _ := n.MarshalJSON()
+	b, _ := again.MarshalJSON()
+	if string(a) != string(b) {
+		t.Errorf("Expected %s, got %s", a, b)
+	}
+
+	for _, data := range []any{"two\nlines", "two  spaces", " leading", "trailing ", "tab\there", "", 42, true} {
+		bad := gonode.NewNode()
+		bad.NewChildWithData(data)
+		if err := bad.WriteOutline(&strings.Builder{}); err == nil {
+			t.Errorf("Expected error for data %#v", data)
+		}
+	}
+	for _, tag := range []string{"has space", "a\u00a0b", "a\vb", "a\fb", "a\u2003b", ""} {
+		bad := gonode.NewNode()
+		bad.NewChildWithTags(tag)
+		if err := bad.WriteOutline(&strings.Builder{}); err == nil {
+			t.Errorf("Expected error for the tag %q", tag)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package gonode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Secret single operation of a json patch
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Applies a json patch document (RFC 6902) to this Node, using the same layout as FromJSONValue (see Pointer)
+//
+// Supports the add, remove, replace, move, copy and test operations.
+// The patch is all or nothing, on error (including a failed test) this Node is left untouched.
+// Nodes the patch doesn't touch stay the same Nodes, so pointers to them are still good afterwards
+func (n *Node) ApplyPatch(patch []byte) error {
+	var ops []patchOp
+	err := json.Unmarshal(patch, &ops)
+	if err != nil {
+		return fmt.Errorf("invalid json patch: %w", err)
+	}
+	// Tried on a copy first, operations only depend on the content so they can't fail the second time
+	err = n.Clone().applyOps(ops)
+	if err != nil {
+		return err
+	}
+	return n.applyOps(ops)
+}
+
+// Secret util for applying the operations of a json patch in order, stopping at the first error
+func (n *Node) applyOps(ops []patchOp) error {
+	for idx, op := range ops {
+		err := n.applyOp(op)
+		if err != nil {
+			return fmt.Errorf("patch operation %d (%s): %w", idx, op.Op, err)
+		}
+	}
+	return nil
+}
+
+// Secret util for applying a single operation of a json patch
+func (n *Node) applyOp(op patchOp) error {
+	if op.Path == nil {
+		return errors.New("missing path")
+	}
+	path, err := pointerTokens(*op.Path)
+	if err != nil {
+		return err
+	}
+	var from []string
+	switch op.Op {
+	case "move", "copy":
+		if op.From == nil {
+			return errors.New("missing from")
+		}
+		from, err = pointerTokens(*op.From)
+		if err != nil {
+			return err
+		}
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return errors.New("missing value")
+		}
+	}
+	switch op.Op {
+	case "add":
+		o, err := patchValue(op.Value)
+		if err != nil {
+			return err
+		}
+		return n.patchAdd(path, o, false)
+	case "remove":
+		_, err := n.patchRemove(path)
+		return err
+	case "replace":
+		o, err := patchValue(op.Value)
+		if err != nil {
+			return err
+		}
+		return n.patchAdd(path, o, true)
+	case "move":
+		if *op.From == *op.Path {
+			_, err := n.pointer(path)
+			return err
+		}
+		if strings.HasPrefix(*op.Path, *op.From+"/") {
+			return errors.New("can't move a value into itself")
+		}
+		o, err := n.patchRemove(from)
+		if err != nil {
+			return err
+		}
+		return n.patchAdd(path, o, false)
+	case "copy":
+		o, err := n.pointer(from)
+		if err != nil {
+			return err
+		}
+		return n.patchAdd(path, o.Clone(), false)
+	case "test":
+		o, err := n.pointer(path)
+		if err != nil {
+			return err
+		}
+		have, err := o.toValue(*op.Path)
+		if err != nil {
+			return err
+		}
+		got, err := canonicalJSON(have)
+		if err != nil {
+			return err
+		}
+		var v any
+		err = json.Unmarshal(op.Value, &v)
+		if err != nil {
+			return err
+		}
+		want, err := canonicalJSON(v)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(got, want) {
+			return fmt.Errorf("%s: test failed, expected %s, got %s", pathOrRoot(*op.Path), want, got)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// Secret util for making a new Node from the value of an operation
+func patchValue(raw json.RawMessage) (*Node, error) {
+	var v any
+	err := json.Unmarshal(raw, &v)
+	if err != nil {
+		return nil, err
+	}
+	o := &Node{}
+	err = o.fromValue(v, "")
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Secret util for adding (or replacing) the given Node at the pointer (split into tokens)
+//
+// Added to an object the Node is tagged by it's key, with replace set the value must already exist
+func (n *Node) patchAdd(path []string, o *Node, replace bool) error {
+	if len(path) == 0 {
+		o.tags = n.tags
+		n.replaceWith(o)
+		return nil
+	}
+	parentPath, last := path[:len(path)-1], path[len(path)-1]
+	parent, err := n.pointer(parentPath)
+	if err != nil {
+		return err
+	}
+	at := "/" + strings.Join(parentPath, "/")
+	if len(parentPath) == 0 {
+		at = ""
+	}
+	kind, err := parent.containerKind(at)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case kindObject:
+		o.tags = []string{last}
+		idx, err := parent.pointerChild(last, at)
+		if err == nil {
+			parent.ReplaceChild(idx, o)
+			return nil
+		}
+		if replace {
+			return err
+		}
+		parent.AddChild(o)
+		return nil
+	case kindArray:
+		if replace {
+			idx, err := parent.pointerChild(last, at)
+			if err != nil {
+				return err
+			}
+			parent.ReplaceChild(idx, o)
+			return nil
+		}
+		idx := parent.Len()
+		if last != "-" {
+			idx, err = arrayIndex(last, parent.Len())
+			if err != nil {
+				return fmt.Errorf("%s/%s: %w", at, escapePointer(last), err)
+			}
+		}
+		parent.insertChild(idx, o)
+		return nil
+	}
+	return fmt.Errorf("%s: not an object or array", pathOrRoot(at))
+}
+
+// Secret util for removing the Node at the pointer (split into tokens), returning it
+func (n *Node) patchRemove(path []string) (*Node, error) {
+	if len(path) == 0 {
+		return nil, errors.New("can't remove the whole document")
+	}
+	o, err := n.pointer(path)
+	if err != nil {
+		return nil, err
+	}
+	o.Detach()
+	return o, nil
+}
+
+// Secret util for placing the given Node at the given index of the children
+func (n *Node) insertChild(idx int, o *Node) {
+	n.children = append(n.children, nil)
+	copy(n.children[idx+1:], n.children[idx:])
+	n.children[idx] = o
+	o.parent = n
+	n.indexChild(o)
+}
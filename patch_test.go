@@ -0,0 +1,123 @@
+package gonode_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func patchTree(t *testing.T, doc string) *gonode.Node {
+	var v any
+	err := json.Unmarshal([]byte(doc), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := gonode.FromJSONValue(v)
+	if err != nil {
+		t.Fatalf("FromJSONValue %v", err)
+	}
+	return n
+}
+
+func TestApplyPatch(t *testing.T) {
+	// Mostly from the examples of RFC 6902
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":{"a":1}}`, `[{"op":"copy","from":"/foo","path":"/bar"},{"op":"add","path":"/bar/b","value":2}]`, `{"bar":{"a":1,"b":2},"foo":{"a":1}}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"child":{"grandchild":{}},"foo":"bar"}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":null}]`, `{"baz":null,"foo":"bar"}`},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"","value":[1,2]}]`, `[1,2]`},
+	}
+	for _, test := range tests {
+		n := patchTree(t, test.doc)
+		err := n.ApplyPatch([]byte(test.patch))
+		if err != nil {
+			t.Errorf("%s: %v", test.patch, err)
+			continue
+		}
+		if !n.HasTag("root") {
+			t.Errorf("%s: expected the tags kept", test.patch)
+		}
+		got, err := n.ToJSONValue()
+		if err != nil {
+			t.Errorf("%s: ToJSONValue %v", test.patch, err)
+			continue
+		}
+		var want any
+		json.Unmarshal([]byte(test.want), &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v, got %v", test.patch, want, got)
+		}
+		for kid := range n.Iter() {
+			if kid.Parent() != n {
+				t.Errorf("%s: expected parents updated", test.patch)
+			}
+		}
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	doc := `{"foo":["bar","baz"],"q":{"bar":2}}`
+	tests := []string{
+		`{"op":"add"}`,
+		`[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+		`[{"op":"add","path":"/foo/3","value":"qux"}]`,
+		`[{"op":"remove","path":"/nope"}]`,
+		`[{"op":"replace","path":"/nope","value":1}]`,
+		`[{"op":"add","path":"/x"}]`,
+		`[{"op":"move","from":"/q","path":"/q/bar/x"}]`,
+		`[{"op":"test","path":"/q","value":{"bar":3}}]`,
+		`[{"op":"frob","path":"/q"}]`,
+		`[{"op":"remove","path":""}]`,
+		// Fails after the first operation worked
+		`[{"op":"add","path":"/new","value":1},{"op":"test","path":"/foo/0","value":"nope"}]`,
+	}
+	for _, patch := range tests {
+		n := patchTree(t, doc)
+		before, _ := n.MarshalJSON()
+		err := n.ApplyPatch([]byte(patch))
+		if err == nil {
+			t.Errorf("%s: expected error", patch)
+			continue
+		}
+		t.Logf("%s: %v", patch, err)
+		after, _ := n.MarshalJSON()
+		if string(before) != string(after) {
+			t.Errorf("%s: expected the Node untouched, got %s", patch, after)
+		}
+	}
+	err := patchTree(t, doc).ApplyPatch([]byte(`[{"op":"test","path":"/q/bar","value":3}]`))
+	if err == nil || !strings.Contains(err.Error(), "test failed") {
+		t.Errorf("Expected a failed test to say so, got %v", err)
+	}
+}
+
+func TestApplyPatchKeepsNodes(t *testing.T) {
+	n := patchTree(t, `{"a":{"b":1},"list":[1,2]}`)
+	a := n.ChildByTag("a")
+	b := a.ChildByTag("b")
+	list := n.ChildByTag("list")
+	err := n.ApplyPatch([]byte(`[{"op":"add","path":"/x","value":1},{"op":"add","path":"/a/c","value":2},{"op":"remove","path":"/list/0"}]`))
+	if err != nil {
+		t.Fatalf("ApplyPatch %v", err)
+	}
+	if a.Parent() != n || b.Parent() != a || list.Parent() != n {
+		t.Errorf("Expected untouched Nodes kept in the tree")
+	}
+	if a.ChildByTag("c") == nil || list.Len() != 1 || n.ChildByTag("x") == nil {
+		t.Errorf("Expected the patch applied to the held Nodes")
+	}
+}
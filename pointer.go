@@ -0,0 +1,99 @@
+package gonode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Finds the Node a json pointer (RFC 6901, i.e. "/servers/0/name") refers to, from this Node
+//
+// Uses the same layout as FromJSONValue: object keys are the first tag of a child, array indexes are the child's index.
+// The empty pointer "" is this Node
+func (n *Node) Pointer(ptr string) (*Node, error) {
+	toks, err := pointerTokens(ptr)
+	if err != nil {
+		return nil, err
+	}
+	return n.pointer(toks)
+}
+
+// Secret util for following already split pointer tokens
+func (n *Node) pointer(toks []string) (*Node, error) {
+	at := n
+	path := ""
+	for _, tok := range toks {
+		idx, err := at.pointerChild(tok, path)
+		if err != nil {
+			return nil, err
+		}
+		at = at.children[idx]
+		path += "/" + escapePointer(tok)
+	}
+	return at, nil
+}
+
+// Secret util for splitting a json pointer into it's (unescaped) tokens
+func pointerTokens(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("json pointer %q must start with /", ptr)
+	}
+	toks := strings.Split(ptr[1:], "/")
+	for idx, tok := range toks {
+		toks[idx] = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+	}
+	return toks, nil
+}
+
+// Secret util for escaping a token of a json pointer
+func escapePointer(tok string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(tok, "~", "~0"), "/", "~1")
+}
+
+// Secret util for the kind of value this Node is, an empty Node counts as an (empty) object
+func (n *Node) containerKind(path string) (string, error) {
+	kind, err := n.kind(path)
+	if err == nil && kind == "" && n.data == nil {
+		kind = kindObject
+	}
+	return kind, err
+}
+
+// Secret util for the index of the child a pointer token refers to
+func (n *Node) pointerChild(tok, path string) (int, error) {
+	kind, err := n.containerKind(path)
+	if err != nil {
+		return -1, err
+	}
+	switch kind {
+	case kindObject:
+		for idx, kid := range n.children {
+			if len(kid.tags) != 0 && kid.tags[0] == tok {
+				return idx, nil
+			}
+		}
+		return -1, fmt.Errorf("%s/%s: no such key", path, escapePointer(tok))
+	case kindArray:
+		idx, err := arrayIndex(tok, n.Len()-1)
+		if err != nil {
+			return -1, fmt.Errorf("%s/%s: %w", path, escapePointer(tok), err)
+		}
+		return idx, nil
+	}
+	return -1, fmt.Errorf("%s: not an object or array", pathOrRoot(path))
+}
+
+// Secret util for parsing an array index of a json pointer, which can't be more than max
+func arrayIndex(tok string, max int) (int, error) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') || strings.Trim(tok, "0123456789") != "" {
+		return -1, fmt.Errorf("invalid array index")
+	}
+	idx, err := strconv.Atoi(tok)
+	if err != nil || idx > max {
+		return -1, fmt.Errorf("array index out of range")
+	}
+	return idx, nil
+}
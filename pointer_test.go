@@ -0,0 +1,57 @@
+package gonode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestPointer(t *testing.T) {
+	// From RFC 6901
+	var v any
+	err := json.Unmarshal([]byte(`{"foo": ["bar", "baz"], "": 0, "a/b": 1, "c%d": 2, "e^f": 3, "g|h": 4, "i\\j": 5, "k\"l": 6, " ": 7, "m~n": 8}`), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := gonode.FromJSONValue(v)
+	if err != nil {
+		t.Fatalf("FromJSONValue %v", err)
+	}
+	tests := map[string]any{
+		"/foo/0": "bar",
+		"/":      0.0,
+		"/a~1b":  1.0,
+		"/c%d":   2.0,
+		"/e^f":   3.0,
+		"/g|h":   4.0,
+		"/i\\j":  5.0,
+		"/k\"l":  6.0,
+		"/ ":     7.0,
+		"/m~0n":  8.0,
+	}
+	for ptr, want := range tests {
+		o, err := n.Pointer(ptr)
+		if err != nil {
+			t.Errorf("%s: %v", ptr, err)
+			continue
+		}
+		if o.Data() != want {
+			t.Errorf("%s: expected %v, got %v", ptr, want, o.Data())
+		}
+	}
+	if o, err := n.Pointer(""); err != nil || o != n {
+		t.Errorf("Expected the empty pointer to be the Node itself, got %v", err)
+	}
+	if o, err := n.Pointer("/foo"); err != nil || o.Len() != 2 {
+		t.Errorf("Expected the array, got %v", err)
+	}
+
+	for _, ptr := range []string{"foo", "/nope", "/foo/2", "/foo/01", "/foo/-", "/foo/0/x"} {
+		if _, err := n.Pointer(ptr); err == nil {
+			t.Errorf("%s: expected error", ptr)
+		} else {
+			t.Logf("%s: %v", ptr, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package gonode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Options for Render, the zero value shows everything
+type RenderOptions struct {
+	MaxDepth    int  // How many levels below the Node to show, 0 (or less) is no limit
+	MaxChildren int  // How many children of each Node to show, 0 (or less) is no limit
+	MaxData     int  // How many characters of data to show, 0 (or less) is no limit
+	Attrs       bool // Show attributes (as {key=value})
+	ASCII       bool // Only use ascii (for logs), i.e. "|--" rather than "├──"
+}
+
+// Secret set of lines (and dots) a tree is drawn with
+type renderLines struct {
+	branch, last, down, space, more string
+}
+
+var (
+	unicodeLines = renderLines{"├── ", "└── ", "│   ", "    ", "…"}
+	asciiLines   = renderLines{"|-- ", "`-- ", "|   ", "    ", "..."}
+)
+
+// Writes this Node (and everything below it) like the tree command does, showing tags and data
+//
+//	root
+//	├── name: "web"
+//	└── servers
+//	    ├── 80
+//	    └── … 2 more
+//
+// Tags are joined by commas, strings are quoted and other data is formatted with fmt.Sprint
+func (n *Node) Render(w io.Writer, opts RenderOptions) error {
+	r := &renderer{w: bufio.NewWriter(w), opts: opts, lines: unicodeLines}
+	if opts.ASCII {
+		r.lines = asciiLines
+	}
+	r.w.WriteString(r.label(n) + "\n")
+	r.children(n, "", 1)
+	return r.w.Flush()
+}
+
+// Secret writer of Render
+type renderer struct {
+	w     *bufio.Writer
+	opts  RenderOptions
+	lines renderLines
+}
+
+// Secret util for writing the children of a Node, each line starting with prefix
+func (r *renderer) children(n *Node, prefix string, depth int) {
+	if n.Len() == 0 {
+		return
+	}
+	if r.opts.MaxDepth > 0 && depth > r.opts.MaxDepth {
+		r.w.WriteString(prefix + r.lines.last + r.lines.more + " " + plural(n.Len(), "child", "children") + "\n")
+		return
+	}
+	shown := n.children
+	if r.opts.MaxChildren > 0 && len(shown) > r.opts.MaxChildren {
+		shown = shown[:r.opts.MaxChildren]
+	}
+	for idx, kid := range shown {
+		last := idx == n.Len()-1
+		branch, down := r.lines.branch, r.lines.down
+		if last {
+			branch, down = r.lines.last, r.lines.space
+		}
+		r.w.WriteString(prefix + branch + r.label(kid) + "\n")
+		r.children(kid, prefix+down, depth+1)
+	}
+	if left := n.Len() - len(shown); left > 0 {
+		r.w.WriteString(prefix + r.lines.last + r.lines.more + " " + strconv.Itoa(left) + " more\n")
+	}
+}
+
+// Secret util for the text shown for a Node
+func (r *renderer) label(n *Node) string {
+	parts := []string{}
+	if len(n.tags) != 0 {
+		tags := make([]string, 0, len(n.tags))
+		for _, tag := range n.tags {
+			tags = append(tags, r.escape(tag))
+		}
+		parts = append(parts, strings.Join(tags, ", "))
+	}
+	if n.data != nil {
+		parts = append(parts, r.data(n.data))
+	}
+	text := strings.Join(parts, ": ")
+	if text == "" {
+		text = "(empty)"
+	}
+	if r.opts.Attrs && len(n.attrs) != 0 {
+		keys := make([]string, 0, len(n.attrs))
+		for k := range n.attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		atrs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			if r.opts.ASCII {
+				atrs = append(atrs, r.escape(k)+"="+strconv.QuoteToASCII(n.attrs[k]))
+			} else {
+				atrs = append(atrs, r.escape(k)+"="+strconv.Quote(n.attrs[k]))
+			}
+		}
+		text += " {" + strings.Join(atrs, ", ") + "}"
+	}
+	return text
+}
+
+// Secret util for the text shown for data, on a single line and cut to MaxData
+func (r *renderer) data(data any) string {
+	text := dataText(data, r.opts.MaxData, r.lines.more)
+	switch data.(type) {
+	case string, []byte:
+		if r.opts.ASCII {
+			return strconv.QuoteToASCII(text)
+		}
+		return strconv.Quote(text)
+	}
+	return r.escape(text)
+}
+
+// Secret util for escaping text like strconv.Quote (strconv.QuoteToASCII with ASCII set), without the quotes
+//
+// Only text that would break the lines (or isn't ASCII when wanted) is escaped, the rest is kept as is
+func (r *renderer) escape(text string) string {
+	for _, c := range text {
+		if !unicode.IsPrint(c) || (r.opts.ASCII && c >= utf8.RuneSelf) {
+			q := strconv.Quote(text)
+			if r.opts.ASCII {
+				q = strconv.QuoteToASCII(text)
+			}
+			return q[1 : len(q)-1]
+		}
+	}
+	return text
+}
+
+// Secret util for the text of data cut to max characters (ending with more), strings and []byte are as is
+func dataText(data any, max int, more string) string {
+	var text string
+	switch d := data.(type) {
+	case string:
+		text = d
+	case []byte:
+		text = string(d)
+	default:
+		text = fmt.Sprint(data)
+	}
+	if max > 0 && utf8.RuneCountInString(text) > max {
+		text = string([]rune(text)[:max]) + more
+	}
+	return text
+}
+
+// Secret util for counting things, i.e. "1 child" or "2 children"
+func plural(count int, one, many string) string {
+	if count == 1 {
+		return "1 " + one
+	}
+	return strconv.Itoa(count) + " " + many
+}
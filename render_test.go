@@ -0,0 +1,117 @@
+package gonode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func renderTree() *gonode.Node {
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags("web", "name")
+	servers := n.NewChildWithTags("servers")
+	servers.SetAttr(gonode.KindAttr, "array")
+	for _, port := range []int{80, 443, 8080, 8443} {
+		servers.NewChildWithData(port)
+	}
+	deep := n.NewChildWithTags("deep", "er")
+	deep.NewChild().NewChildWithData("héllo\nthere")
+	return n
+}
+
+func TestRender(t *testing.T) {
+	buf := &strings.Builder{}
+	err := renderTree().Render(buf, gonode.RenderOptions{})
+	if err != nil {
+		t.Fatalf("Render %v", err)
+	}
+	want := `root
+├── name: "web"
+├── servers
+│   ├── 80
+│   ├── 443
+│   ├── 8080
+│   └── 8443
+└── deep, er
+    └── (empty)
+        └── "héllo\nthere"
+`
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestRenderOptions(t *testing.T) {
+	buf := &strings.Builder{}
+	err := renderTree().Render(buf, gonode.RenderOptions{
+		MaxDepth:    2,
+		MaxChildren: 2,
+		MaxData:     2,
+		Attrs:       true,
+		ASCII:       true,
+	})
+	if err != nil {
+		t.Fatalf("Render %v", err)
+	}
+	want := "root\n" +
+		"|-- name: \"we...\"\n" +
+		"|-- servers {" + gonode.KindAttr + "=\"array\"}\n" +
+		"|   |-- 80\n" +
+		"|   |-- 44...\n" +
+		"|   `-- ... 2 more\n" +
+		"`-- ... 1 more\n"
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	renderTree().ChildByTag("deep").Render(buf, gonode.RenderOptions{MaxDepth: 1, ASCII: true})
+	want = "deep, er\n" +
+		"`-- (empty)\n" +
+		"    `-- ... 1 child\n"
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestRenderASCIIEscapes(t *testing.T) {
+	n := gonode.NewNodeWithTags("café")
+	n.RmTag("root")
+	n.NewChildWithDataAndTags([]any{"ünï"}, "naïve")
+	n.NewChildWithDataAndTags("日本", "plain").SetAttr("ключ", "значение")
+	buf := &strings.Builder{}
+	err := n.Render(buf, gonode.RenderOptions{Attrs: true, ASCII: true})
+	if err != nil {
+		t.Fatalf("Render %v", err)
+	}
+	for _, r := range buf.String() {
+		if r > 127 {
+			t.Fatalf("Expected only ascii, got:\n%s", buf.String())
+		}
+	}
+	want := "caf\\u00e9\n" +
+		"|-- na\\u00efve: [\\u00fcn\\u00ef]\n" +
+		"`-- plain: \"\\u65e5\\u672c\" {\\u043a\\u043b\\u044e\\u0447=\"\\u0437\\u043d\\u0430\\u0447\\u0435\\u043d\\u0438\\u0435\"}\n"
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestRenderEscapes(t *testing.T) {
+	n := gonode.NewNodeWithTags("two\nlines")
+	n.RmTag("root")
+	n.NewChildWithDataAndTags(42, "tab\there").SetAttr("key\r", "v")
+	n.NewChildWithDataAndTags([]any{"a\nb"}, "café")
+	buf := &strings.Builder{}
+	err := n.Render(buf, gonode.RenderOptions{Attrs: true})
+	if err != nil {
+		t.Fatalf("Render %v", err)
+	}
+	want := "two\\nlines\n" +
+		"├── tab\\there: 42 {key\\r=\"v\"}\n" +
+		"└── café: [a\\nb]\n"
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
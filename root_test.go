@@ -0,0 +1,69 @@
+package gonode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestNodeIsRoot(t *testing.T) {
+	n := gonode.NewNode()
+	a := n.NewChild()
+	b := a.NewChild()
+	if !n.IsRoot() || a.IsRoot() {
+		t.Errorf("Expected only the top Node to be a root")
+	}
+	if n.Depth() != 0 || a.Depth() != 1 || b.Depth() != 2 {
+		t.Errorf("Expected depths 0, 1, 2, got %d, %d, %d", n.Depth(), a.Depth(), b.Depth())
+	}
+	n.RmAllTags()
+	if a.Depth() != 1 {
+		t.Errorf("Expected tags not to change depth, got %d", a.Depth())
+	}
+	a.Detach()
+	if !a.IsRoot() || a.Depth() != 0 || b.Depth() != 1 {
+		t.Errorf("Expected detached Node to be a root, got depths %d, %d", a.Depth(), b.Depth())
+	}
+	if a.HasTag("root") {
+		t.Errorf("Detached Node shouldn't gain the 'root' tag")
+	}
+}
+
+func TestRootTag(t *testing.T) {
+	defer func(old string) { gonode.RootTag = old }(gonode.RootTag)
+
+	gonode.RootTag = "top"
+	n := gonode.NewNodeWithTags("kid")
+	if !n.HasTag("top", "kid") || n.HasTag("root") {
+		t.Errorf("Expected 'top' and 'kid' as tags, got '%s'", n.Tags())
+	}
+	// Legacy files always carry "root"
+	dummy := gonode.NewNode()
+	err := json.Unmarshal([]byte(`{"Tags": ["root", "kid"], "Children": [{"Tags": ["root"]}]}`), &dummy)
+	if err != nil {
+		t.Fatalf("json.Unmarshal %v", err)
+	}
+	if !dummy.HasTag("top", "kid") || dummy.HasTag("root") {
+		t.Errorf("Expected legacy 'root' swapped for 'top', got '%s'", dummy.Tags())
+	}
+	if !dummy.Child(0).HasTag("root") {
+		t.Errorf("Expected only the top Node to be fixed, got '%s'", dummy.Child(0).Tags())
+	}
+	// Merging isn't decoding, it leaves "root" alone
+	o := gonode.NewNodeWithTags("kid")
+	o.RmTag("top")
+	o.AddTag("root")
+	if !dummy.Merge(o, gonode.ModeReplace) || !dummy.HasTag("root", "kid") || dummy.HasTag("top") {
+		t.Errorf("Expected Merge to keep the tags as given, got '%s'", dummy.Tags())
+	}
+
+	gonode.RootTag = ""
+	n = gonode.NewNodeWithData(42)
+	if len(n.Tags()) != 0 {
+		t.Errorf("Expected no tags, got '%s'", n.Tags())
+	}
+	if !n.IsRoot() {
+		t.Errorf("Expected a root without the tag")
+	}
+}
@@ -0,0 +1,74 @@
+package gonode
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+)
+
+// The attribute holding the (base64) ed25519 signature of a Node, see Sign
+//
+// Being an attribute it's kept by every format (json, yaml, binary, cbor and friends)
+const SignatureAttr = "sig:ed25519"
+
+var (
+	ErrNotSigned    = errors.New("not signed")    // A Node has no signature
+	ErrBadSignature = errors.New("bad signature") // A Node's signature doesn't match (changed or signed by another key)
+)
+
+// Secret util for if this Node has a signature
+func (n *Node) signed() bool {
+	_, ok := n.attrs[SignatureAttr]
+	return ok
+}
+
+// Secret util for what gets signed, the canonical json of this Node (and everything below it) without it's own signature
+//
+// Signatures of Nodes below are included, so signed subtrees can be signed again as a whole
+func (n *Node) signingInput() ([]byte, error) {
+	enc := &Encoder{}
+	enc.SetCanonical(true)
+	enc.unsigned = n
+	return enc.bytes(n)
+}
+
+// Signs this Node (and everything below it) with the given key, storing the signature as the SignatureAttr attribute
+//
+// The canonical json (see MarshalCanonicalJSON) is signed, so the tree can be stored in any format and still verify.
+// Any change below this Node afterwards (including to tag order) breaks the signature, sign again after changes
+func (n *Node) Sign(priv ed25519.PrivateKey) error {
+	if len(priv) != ed25519.PrivateKeySize {
+		return errors.New("invalid ed25519 private key")
+	}
+	msg, err := n.signingInput()
+	if err != nil {
+		return err
+	}
+	n.SetAttr(SignatureAttr, base64.StdEncoding.EncodeToString(ed25519.Sign(priv, msg)))
+	return nil
+}
+
+// Verifies the signature of this Node (and everything below it) from Sign with the given key
+//
+// Returns ErrNotSigned when there is no signature, ErrBadSignature when it doesn't match
+func (n *Node) Verify(pub ed25519.PublicKey) error {
+	sig64, ok := n.attrs[SignatureAttr]
+	if !ok {
+		return ErrNotSigned
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return errors.New("invalid ed25519 public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(sig64)
+	if err != nil {
+		return ErrBadSignature
+	}
+	msg, err := n.signingInput()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, msg, sig) {
+		return ErrBadSignature
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package gonode_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func signedTree(t *testing.T, priv ed25519.PrivateKey) *gonode.Node {
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags(42, "answer")
+	part := n.NewChildWithTags("part")
+	part.NewChildWithDataAndTags("<b>bold</b>", "text")
+	part.SetAttr("lang", "en")
+	if err := part.Sign(priv); err != nil {
+		t.Fatalf("Sign %v", err)
+	}
+	if err := n.Sign(priv); err != nil {
+		t.Fatalf("Sign %v", err)
+	}
+	return n
+}
+
+func TestSignVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, _ := ed25519.GenerateKey(nil)
+	n := signedTree(t, priv)
+	if err := n.Verify(pub); err != nil {
+		t.Errorf("Expected to verify, got %v", err)
+	}
+	if err := n.ChildByTag("part").Verify(pub); err != nil {
+		t.Errorf("Expected subtree to verify, got %v", err)
+	}
+	if err := n.Verify(other); !errors.Is(err, gonode.ErrBadSignature) {
+		t.Errorf("Expected ErrBadSignature for another key, got %v", err)
+	}
+	if err := n.ChildByTag("answer").Verify(pub); !errors.Is(err, gonode.ErrNotSigned) {
+		t.Errorf("Expected ErrNotSigned, got %v", err)
+	}
+
+	// Survives other formats
+	pay, err := n.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary %v", err)
+	}
+	dummy := gonode.NewNode()
+	if err := dummy.UnmarshalBinary(pay); err != nil {
+		t.Fatalf("UnmarshalBinary %v", err)
+	}
+	if err := dummy.Verify(pub); err != nil {
+		t.Errorf("Expected to verify after binary, got %v", err)
+	}
+
+	// Tampering
+	n.ChildByTag("part").ChildByTag("text").SetData("<b>changed</b>")
+	if err := n.Verify(pub); !errors.Is(err, gonode.ErrBadSignature) {
+		t.Errorf("Expected ErrBadSignature after a change, got %v", err)
+	}
+	if err := n.ChildByTag("part").Verify(pub); !errors.Is(err, gonode.ErrBadSignature) {
+		t.Errorf("Expected ErrBadSignature after a change, got %v", err)
+	}
+}
+
+func TestDecoderVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pay, err := signedTree(t, priv).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON %v", err)
+	}
+	decode := func(input []byte) error {
+		dec := gonode.NewDecoder(bytes.NewReader(input))
+		dec.SetVerifyKey(pub)
+		_, err := dec.Decode()
+		return err
+	}
+	if err := decode(pay); err != nil {
+		t.Errorf("Expected to verify, got %v", err)
+	}
+
+	tampered := bytes.Replace(pay, []byte("bold"), []byte("b0ld"), 1)
+	err = decode(tampered)
+	var de *gonode.DecodeError
+	if !errors.As(err, &de) || !errors.Is(err, gonode.ErrBadSignature) || de.Path != "Children[1]" {
+		t.Errorf("Expected ErrBadSignature at Children[1], got %v", err)
+	}
+
+	unsigned, _ := gonode.NewNode().MarshalJSON()
+	if err := decode(unsigned); !errors.Is(err, gonode.ErrNotSigned) {
+		t.Errorf("Expected ErrNotSigned, got %v", err)
+	}
+
+	// DecodeEach checks the Nodes it hands out
+	stream := `{"Children":[` + strings.TrimSpace(string(pay)) + `]}`
+	dec := gonode.NewDecoder(strings.NewReader(stream))
+	dec.SetVerifyKey(pub)
+	count := 0
+	err = dec.DecodeEach(1, func(n *gonode.Node) error {
+		count += 1
+		return nil
+	})
+	if err != nil || count != 1 {
+		t.Errorf("Expected 1 verified Node, got %d %v", count, err)
+	}
+	stream = `{"Children":[{"Tags":["unsigned"]}]}`
+	dec = gonode.NewDecoder(strings.NewReader(stream))
+	dec.SetVerifyKey(pub)
+	err = dec.DecodeEach(1, func(n *gonode.Node) error {
+		return nil
+	})
+	if !errors.Is(err, gonode.ErrNotSigned) {
+		t.Errorf("Expected ErrNotSigned, got %v", err)
+	}
+}
+
+func TestSignLargeIntegers(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wide, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	for _, data := range []any{int64(1<<62 + 1), int64(1 << 60), uint64(1<<64 - 1), int64(12345678901234567), map[string]any{"big": int64(-1<<62 - 1)}, wide} {
+		n := gonode.NewNodeWithData(data)
+		if err := n.Sign(priv); err != nil {
+			t.Fatalf("Sign %v", err)
+		}
+		pay, err := n.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON %v", err)
+		}
+		dummy := gonode.NewNode()
+		if err := dummy.UnmarshalJSON(pay); err != nil {
+			t.Fatalf("UnmarshalJSON %v", err)
+		}
+		if err := dummy.Verify(pub); err != nil {
+			t.Errorf("%v: expected to verify after a json round trip, got %v", data, err)
+		}
+		dec := gonode.NewDecoder(bytes.NewReader(pay))
+		dec.SetVerifyKey(pub)
+		if _, err := dec.Decode(); err != nil {
+			t.Errorf("%v: expected the Decoder to verify, got %v", data, err)
+		}
+	}
+}
+
+func TestSignRootTag(t *testing.T) {
+	defer func(old string) { gonode.RootTag = old }(gonode.RootTag)
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pay, err := signedTree(t, priv).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON %v", err)
+	}
+
+	gonode.RootTag = "top"
+	dec := gonode.NewDecoder(bytes.NewReader(pay))
+	dec.SetVerifyKey(pub)
+	n, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("Decode %v", err)
+	}
+	if err := n.Verify(pub); err != nil {
+		t.Errorf("Expected the decoded Node to verify, got %v", err)
+	}
+	dummy := gonode.NewNode()
+	if err := dummy.UnmarshalJSON(pay); err != nil {
+		t.Fatalf("UnmarshalJSON %v", err)
+	}
+	if err := dummy.Verify(pub); err != nil || !dummy.HasTag("root") {
+		t.Errorf("Expected signed Nodes to keep their tags and verify, got %v", err)
+	}
+}
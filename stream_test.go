@@ -0,0 +1,119 @@
+package gonode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestEncoder(t *testing.T) {
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags(9.81, "gravity")
+	lvl := n.NewChildWithTags("level 2")
+	lvl.NewChildWithDataAndTags(32, "freezing water").SetAttr("unit", "celsius")
+
+	buf := &bytes.Buffer{}
+	enc := gonode.NewEncoder(buf)
+	for i := 0; i < 2; i += 1 {
+		err := enc.Encode(n)
+		if err != nil {
+			t.Fatalf("Encode %v", err)
+		}
+	}
+	want := `{"Tags":["root"],"Children":[{"Tags":["gravity"],"Data":9.81},{"Tags":["level 2"],"Children":[{"Tags":["freezing water"],"Attrs":{"unit":"celsius"},"Data":32}]}]}` + "\n"
+	if buf.String() != want+want {
+		t.Errorf("Expected %s, got %s", want+want, buf.String())
+	}
+
+	pay, err := json.Marshal(n)
+	if err != nil {
+		t.Fatalf("json.Marshal %v", err)
+	}
+	if string(pay)+"\n" != want {
+		t.Errorf("Expected MarshalJSON to match the Encoder, got %s", pay)
+	}
+
+	dec := gonode.NewDecoder(buf)
+	for i := 0; i < 2; i += 1 {
+		o, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Decode %v", err)
+		}
+		if o.ChildByAttrDeep("unit", "celsius") == nil {
+			t.Errorf("Expected the tree back")
+		}
+	}
+
+	buf.Reset()
+	enc = gonode.NewEncoder(buf)
+	err = enc.Encode(gonode.NewNodeWithData(make(chan int)))
+	if err == nil {
+		t.Errorf("Expected error for data json can't encode")
+	}
+}
+
+func TestEncoderFailure(t *testing.T) {
+	bad := gonode.NewNode()
+	bad.NewChildWithDataAndTags(make(chan int), "x")
+	good := gonode.NewNodeWithTags("ok")
+
+	buf := &bytes.Buffer{}
+	enc := gonode.NewEncoder(buf)
+	if err := enc.Encode(bad); err == nil {
+		t.Fatalf("Expected error for chan data")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing written for a failed Encode, got %s", buf.String())
+	}
+	if err := enc.Encode(good); err != nil {
+		t.Fatalf("Encode %v", err)
+	}
+	if want := `{"Tags":["root","ok"]}` + "\n"; buf.String() != want {
+		t.Errorf("Expected %s, got %s", want, buf.String())
+	}
+}
+
+func TestDecodeEach(t *testing.T) {
+	doc := `{"Tags": ["root"], "Children": [
+		{"Tags": ["a"], "Children": [{"Data": 1}, {"Data": 2}]},
+		{"Tags": ["b"], "Children": [{"Data": 3}]}
+	]}`
+	got := []float64{}
+	dec := gonode.NewDecoder(strings.NewReader(doc))
+	err := dec.DecodeEach(2, func(n *gonode.Node) error {
+		if n.Parent() == nil || n.Parent().Parent() == nil {
+			t.Errorf("Expected Node still attached inside callback")
+		}
+		if n.Parent().Len() != 1 {
+			t.Errorf("Expected earlier siblings to be dropped, got %d children", n.Parent().Len())
+		}
+		got = append(got, n.Data().(float64))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DecodeEach %v", err)
+	}
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("Expected 1, 2, 3, got %v", got)
+	}
+
+	tags := []string{}
+	dec = gonode.NewDecoder(strings.NewReader(doc))
+	err = dec.DecodeEach(1, func(n *gonode.Node) error {
+		tags = append(tags, n.Tags()...)
+		if n.Len() == 0 {
+			t.Errorf("Expected the whole subtree, got no children")
+		}
+		return io.ErrShortWrite
+	})
+	if err != io.ErrShortWrite {
+		t.Errorf("Expected the callback's error, got %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "a" {
+		t.Errorf("Expected to stop after 'a', got %v", tags)
+	}
+}
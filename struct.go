@@ -0,0 +1,453 @@
+package gonode
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Secret field of a struct, as found by structFields
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Secret util for the fields of a struct, following the `gonode:"name,omitempty,inline"` struct tag
+//
+// Inline fields (and embedded structs without a name) have their fields used as if they were this struct's,
+// this struct's own fields win over those of inline ones
+func structFields(t reflect.Type) []structField {
+	fields := []structField{}
+	own := map[string]bool{}
+	type inline struct {
+		at    int // Where in fields they go
+		index []int
+		t     reflect.Type
+	}
+	inlines := []inline{}
+	for idx := 0; idx < t.NumField(); idx += 1 {
+		f := t.Field(idx)
+		tag := f.Tag.Get("gonode")
+		if tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		isInline := ft.Kind() == reflect.Struct && ft != timeType && (f.Anonymous && opts[0] == "" || hasOption(opts, "inline"))
+		if isInline && !(f.Type.Kind() == reflect.Pointer && !f.IsExported()) {
+			inlines = append(inlines, inline{at: len(fields), index: f.Index, t: ft})
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name := opts[0]
+		if name == "" {
+			name = f.Name
+		}
+		own[name] = true
+		fields = append(fields, structField{name: name, index: f.Index, omitEmpty: hasOption(opts, "omitempty")})
+	}
+	// Placed from the last so the earlier places stay put
+	for idx := len(inlines) - 1; idx >= 0; idx -= 1 {
+		in := inlines[idx]
+		more := []structField{}
+		for _, f := range structFields(in.t) {
+			if own[f.name] {
+				continue
+			}
+			f.index = append(append([]int{}, in.index...), f.index...)
+			more = append(more, f)
+		}
+		fields = append(fields[:in.at], append(more, fields[in.at:]...)...)
+	}
+	return fields
+}
+
+// Secret util for if the struct tag options include the given option
+func hasOption(opts []string, opt string) bool {
+	for _, o := range opts[1:] {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// Secret util for getting a field by it's index (through inline pointers)
+//
+// With alloc set nil pointers are filled in, otherwise ok is false when passing a nil pointer
+func fieldByIndex(rv reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for idx, i := range index {
+		if idx != 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(i)
+	}
+	return rv, true
+}
+
+// Makes a new "root" Node from a struct (or pointer to one), using the same layout as FromJSONValue
+//
+// Fields become children tagged by their name, structs and maps become objects and slices become arrays.
+// Anything else (including time.Time) is kept as data with it's Go type.
+//
+// Use the struct tag `gonode:"name,omitempty,inline"` to rename a field, leave it out when empty
+// or have the fields of a struct field used as if they were this struct's (embedded structs are already inline).
+// Fields tagged `gonode:"-"` are skipped. Use Decode to get the struct back
+func FromStruct(v any) (*Node, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("FromStruct needs a struct, got %T", v)
+	}
+	n := NewNode()
+	err := n.fromReflect(reflect.ValueOf(v), "", map[reflectVisit]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Secret key of a pointer, map or slice being walked by fromReflect (for finding cycles)
+type reflectVisit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// Secret util for filling this Node from a Go value
+//
+// The pointers, maps and slices being walked are kept in seen, meeting one again is a cycle (an error).
+// Only the way down is kept, so values shared by several fields are fine
+func (n *Node) fromReflect(rv reflect.Value, path string, seen map[reflectVisit]bool) error {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Kind() == reflect.Pointer {
+			key := reflectVisit{ptr: rv.Pointer(), typ: rv.Type()}
+			if seen[key] {
+				return fmt.Errorf("%s: cycle through %s", pathOrRoot(path), rv.Type())
+			}
+			seen[key] = true
+			defer delete(seen, key)
+		}
+		rv = rv.Elem()
+	}
+	if (rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice) && rv.Len() != 0 {
+		key := reflectVisit{ptr: rv.Pointer(), typ: rv.Type(), len: rv.Len()}
+		if seen[key] {
+			return fmt.Errorf("%s: cycle through %s", pathOrRoot(path), rv.Type())
+		}
+		seen[key] = true
+		defer delete(seen, key)
+	}
+	switch {
+	case rv.Kind() == reflect.Struct && rv.Type() != timeType:
+		n.SetAttr(KindAttr, kindObject)
+		for _, f := range structFields(rv.Type()) {
+			fv, ok := fieldByIndex(rv, f.index, false)
+			if !ok || (f.omitEmpty && isEmpty(fv)) {
+				continue
+			}
+			err := n.NewChildWithTags(f.name).fromReflect(fv, path+"/"+f.name, seen)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		n.SetAttr(KindAttr, kindObject)
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		for _, k := range keys {
+			err := n.NewChildWithTags(k.String()).fromReflect(rv.MapIndex(k), path+"/"+k.String(), seen)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8:
+		n.SetAttr(KindAttr, kindArray)
+		for idx := 0; idx < rv.Len(); idx += 1 {
+			err := n.NewChild().fromReflect(rv.Index(idx), path+"/"+strconv.Itoa(idx), seen)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err := n.SetData(rv.Interface())
+	if err != nil {
+		return fmt.Errorf("%s: %w", pathOrRoot(path), err)
+	}
+	return nil
+}
+
+// Secret util for if a value counts as empty for omitempty
+func isEmpty(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.String:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// Fills the given pointer (usually to a struct) from this Node, the reverse of FromStruct
+//
+// Also reads trees from FromJSONValue (and friends), numbers convert between Go types when they fit.
+// Fields without a child are left as they are, a child that doesn't fit it's field returns an error naming where
+func (n *Node) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("Decode needs a non-nil pointer, got %T", v)
+	}
+	return n.decodeReflect(rv.Elem(), "")
+}
+
+// Secret util for filling a Go value from this Node
+func (n *Node) decodeReflect(rv reflect.Value, path string) error {
+	mismatch := func(what any) error {
+		return fmt.Errorf("%s: can't decode %s into %s", pathOrRoot(path), what, rv.Type())
+	}
+	kind, err := n.kind(path)
+	if err != nil {
+		return err
+	}
+	if kind == "" && n.data == nil {
+		// null
+		switch rv.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		return nil
+	}
+	if n.data != nil && reflect.TypeOf(n.data).AssignableTo(rv.Type()) {
+		rv.Set(reflect.ValueOf(n.data))
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return n.decodeReflect(rv.Elem(), path)
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return mismatch(kindOrType(kind, n.data))
+		}
+		val, err := n.toValue(path)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(val))
+		return nil
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			s, ok := n.data.(string)
+			if !ok {
+				return mismatch(kindOrType(kind, n.data))
+			}
+			when, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return fmt.Errorf("%s: %w", pathOrRoot(path), err)
+			}
+			rv.Set(reflect.ValueOf(when))
+			return nil
+		}
+		if kind != kindObject {
+			return mismatch(kindOrType(kind, n.data))
+		}
+		for _, f := range structFields(rv.Type()) {
+			kid := n.keyChild(f.name)
+			if kid == nil {
+				continue
+			}
+			fv, _ := fieldByIndex(rv, f.index, true)
+			err := kid.decodeReflect(fv, path+"/"+f.name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if kind != kindObject || rv.Type().Key().Kind() != reflect.String {
+			return mismatch(kindOrType(kind, n.data))
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), n.Len()))
+		}
+		for _, kid := range n.children {
+			if len(kid.tags) == 0 {
+				return fmt.Errorf("%s: child without a tag to use as key", pathOrRoot(path))
+			}
+			key := kid.tags[0]
+			val := reflect.New(rv.Type().Elem()).Elem()
+			err := kid.decodeReflect(val, path+"/"+key)
+			if err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()), val)
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 && kind == "" {
+			s, ok := n.data.(string)
+			if !ok {
+				return mismatch(kindOrType(kind, n.data))
+			}
+			b, err := base64.StdEncoding.DecodeString(s) // How json writes []byte
+			if err != nil {
+				return fmt.Errorf("%s: %w", pathOrRoot(path), err)
+			}
+			if rv.Kind() == reflect.Array {
+				reflect.Copy(rv, reflect.ValueOf(b))
+			} else {
+				rv.SetBytes(b)
+			}
+			return nil
+		}
+		if kind != kindArray {
+			return mismatch(kindOrType(kind, n.data))
+		}
+		if rv.Kind() == reflect.Array {
+			if n.Len() > rv.Len() {
+				return fmt.Errorf("%s: %d items don't fit in %s", pathOrRoot(path), n.Len(), rv.Type())
+			}
+		} else {
+			rv.Set(reflect.MakeSlice(rv.Type(), n.Len(), n.Len()))
+		}
+		for idx, kid := range n.children {
+			err := kid.decodeReflect(rv.Index(idx), path+"/"+strconv.Itoa(idx))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if kind != "" {
+		return mismatch(kind)
+	}
+	if !setScalar(rv, n.data) {
+		return mismatch(fmt.Sprintf("%T", n.data))
+	}
+	return nil
+}
+
+// Secret util for naming what a Node holds in errors
+func kindOrType(kind string, data any) string {
+	if kind != "" {
+		return kind
+	}
+	return fmt.Sprintf("%T", data)
+}
+
+// Secret util for the child keyed (first tag) by the given key
+func (n *Node) keyChild(key string) *Node {
+	for _, kid := range n.children {
+		if len(kid.tags) != 0 && kid.tags[0] == key {
+			return kid
+		}
+	}
+	return nil
+}
+
+// Secret util for assigning bools, strings and numbers (converting between numbers when the value fits)
+func setScalar(rv reflect.Value, data any) bool {
+	dv := reflect.ValueOf(data)
+	switch rv.Kind() {
+	case reflect.Bool:
+		if dv.Kind() != reflect.Bool {
+			return false
+		}
+		rv.SetBool(dv.Bool())
+		return true
+	case reflect.String:
+		if dv.Kind() != reflect.String {
+			return false
+		}
+		rv.SetString(dv.String())
+		return true
+	}
+	var f float64
+	var i int64
+	var u uint64
+	isInt, isUint := false, false
+	switch dv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, isInt = dv.Int(), true
+		f = float64(i)
+		u, isUint = uint64(i), i >= 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, isUint = dv.Uint(), true
+		f = float64(u)
+		i, isInt = int64(u), u <= math.MaxInt64
+	case reflect.Float32, reflect.Float64:
+		f = dv.Float()
+		if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			i, isInt = int64(f), true
+		}
+		if f == math.Trunc(f) && f >= 0 && f < math.MaxUint64 {
+			u, isUint = uint64(f), true
+		}
+	case reflect.String:
+		num, ok := data.(json.Number)
+		if !ok {
+			return false
+		}
+		var err error
+		f, err = num.Float64()
+		if err != nil {
+			return false
+		}
+		i, err = num.Int64()
+		isInt = err == nil
+		u, err = strconv.ParseUint(string(num), 10, 64)
+		isUint = err == nil
+	default:
+		return false
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isInt || rv.OverflowInt(i) {
+			return false
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !isUint || rv.OverflowUint(u) {
+			return false
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		if rv.OverflowFloat(f) {
+			return false
+		}
+		rv.SetFloat(f)
+	default:
+		return false
+	}
+	return true
+}
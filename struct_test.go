@@ -0,0 +1,164 @@
+package gonode_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/beanzilla/gonode"
+)
+
+type structBase struct {
+	ID      int `gonode:"id"`
+	Comment string
+}
+
+type structServer struct {
+	Host string `gonode:"host"`
+	Port uint16 `gonode:"port,omitempty"`
+}
+
+type structConfig struct {
+	structBase
+	Name     string                    `gonode:"name"`
+	Timeout  time.Duration             `gonode:"timeout"`
+	Started  time.Time                 `gonode:"started"`
+	Servers  []structServer            `gonode:"servers"`
+	Labels   map[string]string         `gonode:"labels,omitempty"`
+	Primary  *structServer             `gonode:"primary"`
+	Extra    structServer              `gonode:"extra,inline"`
+	Raw      []byte                    `gonode:"raw"`
+	Any      any                       `gonode:"any"`
+	Secret   string                    `gonode:"-"`
+	Comment  string                    // Wins over the embedded one
+	Limits   map[string]map[string]int `gonode:"limits,omitempty"`
+	internal int
+}
+
+func TestFromStruct(t *testing.T) {
+	when := time.Date(2022, 10, 12, 21, 10, 6, 0, time.UTC)
+	cfg := structConfig{
+		structBase: structBase{ID: 7, Comment: "hidden"},
+		Name:       "web",
+		Timeout:    3 * time.Second,
+		Started:    when,
+		Servers:    []structServer{{Host: "a", Port: 80}, {Host: "b"}},
+		Extra:      structServer{Host: "extra"},
+		Raw:        []byte("hi"),
+		Any:        map[string]any{"x": 1.5},
+		Secret:     "shh",
+		Comment:    "shown",
+	}
+	n, err := gonode.FromStruct(&cfg)
+	if err != nil {
+		t.Fatalf("FromStruct %v", err)
+	}
+	tags := []string{}
+	for kid := range n.Iter() {
+		tags = append(tags, kid.Tags()[0])
+	}
+	want := []string{"id", "name", "timeout", "started", "servers", "primary", "host", "raw", "any", "Comment"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("Expected children %v, got %v", want, tags)
+	}
+	if n.ChildByTag("timeout").Data() != 3*time.Second || n.ChildByTag("started").Data() != when {
+		t.Errorf("Expected Go types kept as data")
+	}
+	servers := n.ChildByTag("servers")
+	if servers.Len() != 2 || servers.Child(1).ChildByTag("port") != nil || servers.Child(0).ChildByTag("port").Data() != uint16(80) {
+		t.Errorf("Expected omitempty to leave out the port of b")
+	}
+	if n.ChildByTag("Comment").Data() != "shown" {
+		t.Errorf("Expected the outer field to win")
+	}
+
+	// Through json and back
+	v, err := n.ToJSONValue()
+	if err != nil {
+		t.Fatalf("ToJSONValue %v", err)
+	}
+	pay, _ := json.Marshal(v)
+	var raw any
+	json.Unmarshal(pay, &raw)
+	back, err := gonode.FromJSONValue(raw)
+	if err != nil {
+		t.Fatalf("FromJSONValue %v", err)
+	}
+	for _, tree := range []*gonode.Node{n, back} {
+		got := structConfig{}
+		err = tree.Decode(&got)
+		if err != nil {
+			t.Fatalf("Decode %v", err)
+		}
+		cfg.Secret = ""
+		cfg.structBase.Comment = ""
+		if !reflect.DeepEqual(got, cfg) {
+			t.Errorf("Expected %+v, got %+v", cfg, got)
+		}
+	}
+
+	if _, err := gonode.FromStruct(42); err == nil {
+		t.Errorf("Expected error for a non-struct")
+	}
+}
+
+func TestDecodeMismatch(t *testing.T) {
+	tests := map[string]any{
+		`{"host": 42}`:                &structServer{},
+		`{"port": -1}`:                &structServer{},
+		`{"port": 70000}`:             &structServer{},
+		`{"port": 1.5}`:               &structServer{},
+		`{"servers": {"a": 1}}`:       &structConfig{},
+		`{"servers": [{"host": []}]}`: &structConfig{},
+		`{"started": "yesterday"}`:    &structConfig{},
+		`{"primary": "a"}`:            &structConfig{},
+	}
+	for doc, v := range tests {
+		var raw any
+		json.Unmarshal([]byte(doc), &raw)
+		n, err := gonode.FromJSONValue(raw)
+		if err != nil {
+			t.Fatalf("FromJSONValue %v", err)
+		}
+		err = n.Decode(v)
+		if err == nil {
+			t.Errorf("%s: expected error", doc)
+		} else {
+			t.Logf("%s: %v", doc, err)
+		}
+	}
+	if err := gonode.NewNode().Decode(structServer{}); err == nil {
+		t.Errorf("Expected error for a non-pointer")
+	}
+}
+
+type structList struct {
+	Name string
+	Next *structList
+}
+
+func TestFromStructCycles(t *testing.T) {
+	a := &structList{Name: "a"}
+	a.Next = a
+	if _, err := gonode.FromStruct(a); err == nil {
+		t.Errorf("Expected error for a pointer cycle")
+	} else {
+		t.Logf("%v", err)
+	}
+	m := map[string]any{}
+	m["self"] = m
+	if _, err := gonode.FromStruct(structConfig{Any: m}); err == nil {
+		t.Errorf("Expected error for a map cycle")
+	}
+
+	// Shared (but not cyclic) values are fine
+	shared := &structServer{Host: "db"}
+	n, err := gonode.FromStruct(struct{ A, B *structServer }{shared, shared})
+	if err != nil {
+		t.Fatalf("FromStruct %v", err)
+	}
+	if n.ChildByTag("A").ChildByTag("host").Data() != "db" || n.ChildByTag("B").ChildByTag("host").Data() != "db" {
+		t.Errorf("Expected the shared value under both fields")
+	}
+}
@@ -0,0 +1,314 @@
+package gonode
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Which way the tree of WriteSVG grows, from the "root" Node
+type Orientation int
+
+const (
+	TopDown Orientation = iota
+	LeftRight
+	BottomUp
+	RightLeft
+)
+
+// Options for WriteSVG, zero values use the defaults
+type SVGOptions struct {
+	Orientation Orientation
+	Label       GraphLabel // What each box shows (see GraphOptions)
+	MaxData     int        // How many characters of data to show, 0 (or less) is no limit
+	LevelGap    float64    // Space between levels (default 40)
+	SiblingGap  float64    // Space between neighbouring boxes (default 16)
+	FontSize    float64    // Size of the text (default 12), boxes are sized to fit it
+}
+
+// Secret Node of the layout, following "Improving Walker's Algorithm to Run in Linear Time" (Buchheim, Jünger and Leipert)
+type layoutNode struct {
+	n           *Node
+	lines       []string
+	parent      *layoutNode
+	kids        []*layoutNode
+	number      int     // Index among it's siblings
+	level       int     // Depth below the top
+	breadth     float64 // Size of the box across the levels
+	depth       float64 // Size of the box along the levels
+	w, h        float64 // Size of the box (as drawn)
+	prelim, mod float64
+	shift       float64
+	change      float64
+	thread      *layoutNode
+	ancestor    *layoutNode
+	x           float64 // Final place across the levels (the center)
+	levelCenter float64 // Final place along the levels (the center)
+}
+
+// Secret tidy tree layout (Reingold–Tilford, in linear time as improved by Buchheim and friends)
+type treeLayout struct {
+	opts   SVGOptions
+	levels []float64 // Size of each level (the deepest box in it)
+}
+
+// Secret util for making the layout Node of a Node (and everything below it)
+func (t *treeLayout) build(n *Node, parent *layoutNode, number, level int) *layoutNode {
+	v := &layoutNode{n: n, parent: parent, number: number, level: level}
+	v.ancestor = v
+	v.lines = n.graphLines(t.opts.Label, t.opts.MaxData)
+	font := t.opts.FontSize
+	v.w = 2 * font
+	for _, line := range v.lines {
+		v.w = math.Max(v.w, float64(utf8.RuneCountInString(line))*font*0.6+font)
+	}
+	v.h = float64(len(v.lines))*font*1.2 + font*0.8
+	v.breadth, v.depth = v.w, v.h
+	if t.opts.Orientation == LeftRight || t.opts.Orientation == RightLeft {
+		v.breadth, v.depth = v.h, v.w
+	}
+	if len(t.levels) <= level {
+		t.levels = append(t.levels, 0)
+	}
+	t.levels[level] = math.Max(t.levels[level], v.depth)
+	for idx, kid := range n.children {
+		v.kids = append(v.kids, t.build(kid, v, idx, level+1))
+	}
+	return v
+}
+
+// Secret util for how far apart the centers of two neighbouring boxes must be
+func (t *treeLayout) distance(a, b *layoutNode) float64 {
+	return (a.breadth+b.breadth)/2 + t.opts.SiblingGap
+}
+
+func (v *layoutNode) leftSibling() *layoutNode {
+	if v.parent == nil || v.number == 0 {
+		return nil
+	}
+	return v.parent.kids[v.number-1]
+}
+
+func (v *layoutNode) nextLeft() *layoutNode {
+	if len(v.kids) != 0 {
+		return v.kids[0]
+	}
+	return v.thread
+}
+
+func (v *layoutNode) nextRight() *layoutNode {
+	if len(v.kids) != 0 {
+		return v.kids[len(v.kids)-1]
+	}
+	return v.thread
+}
+
+// Secret util placing each subtree as close to it's left siblings as they can go (bottom up)
+func (t *treeLayout) firstWalk(v *layoutNode) {
+	w := v.leftSibling()
+	if len(v.kids) == 0 {
+		if w != nil {
+			v.prelim = w.prelim + t.distance(w, v)
+		}
+		return
+	}
+	defaultAncestor := v.kids[0]
+	for _, kid := range v.kids {
+		t.firstWalk(kid)
+		defaultAncestor = t.apportion(kid, defaultAncestor)
+	}
+	t.executeShifts(v)
+	mid := (v.kids[0].prelim + v.kids[len(v.kids)-1].prelim) / 2
+	if w != nil {
+		v.prelim = w.prelim + t.distance(w, v)
+		v.mod = v.prelim - mid
+	} else {
+		v.prelim = mid
+	}
+}
+
+// Secret util that moves the subtree of v clear of the subtrees to it's left, spreading the
+// move over the subtrees between them
+func (t *treeLayout) apportion(v, defaultAncestor *layoutNode) *layoutNode {
+	w := v.leftSibling()
+	if w == nil {
+		return defaultAncestor
+	}
+	// i/o: inside and outside contours, p/m: right (v's) and left subtrees
+	vip, vop := v, v
+	vim, vom := w, v.parent.kids[0]
+	sip, sop := vip.mod, vop.mod
+	sim, som := vim.mod, vom.mod
+	for vim.nextRight() != nil && vip.nextLeft() != nil {
+		vim, vip = vim.nextRight(), vip.nextLeft()
+		vom, vop = vom.nextLeft(), vop.nextRight()
+		vop.ancestor = v
+		shift := (vim.prelim + sim) - (vip.prelim + sip) + t.distance(vim, vip)
+		if shift > 0 {
+			ancestor := defaultAncestor
+			if vim.ancestor.parent == v.parent {
+				ancestor = vim.ancestor
+			}
+			moveSubtree(ancestor, v, shift)
+			sip += shift
+			sop += shift
+		}
+		sim += vim.mod
+		sip += vip.mod
+		som += vom.mod
+		sop += vop.mod
+	}
+	if vim.nextRight() != nil && vop.nextRight() == nil {
+		vop.thread = vim.nextRight()
+		vop.mod += sim - sop
+	}
+	if vip.nextLeft() != nil && vom.nextLeft() == nil {
+		vom.thread = vip.nextLeft()
+		vom.mod += sip - som
+		defaultAncestor = v
+	}
+	return defaultAncestor
+}
+
+// Secret util for moving the subtree of wp right by shift (the siblings between are spread by executeShifts)
+func moveSubtree(wm, wp *layoutNode, shift float64) {
+	subtrees := float64(wp.number - wm.number)
+	wp.change -= shift / subtrees
+	wp.shift += shift
+	wm.change += shift / subtrees
+	wp.prelim += shift
+	wp.mod += shift
+}
+
+// Secret util for applying the moves of apportion to the children of v
+func (t *treeLayout) executeShifts(v *layoutNode) {
+	shift, change := 0.0, 0.0
+	for idx := len(v.kids) - 1; idx >= 0; idx -= 1 {
+		w := v.kids[idx]
+		w.prelim += shift
+		w.mod += shift
+		change += w.change
+		shift += w.shift + change
+	}
+}
+
+// Secret util for the final places (top down), returning the smallest left edge
+func (t *treeLayout) secondWalk(v *layoutNode, m float64, starts []float64) float64 {
+	v.x = v.prelim + m
+	v.levelCenter = starts[v.level] + t.levels[v.level]/2
+	left := v.x - v.breadth/2
+	for _, kid := range v.kids {
+		left = math.Min(left, t.secondWalk(kid, m+v.mod, starts))
+	}
+	return left
+}
+
+// Writes this Node (and everything below it) as a standalone SVG picture of a tidy tree
+//
+// Boxes labelled by tags and/or data (see GraphLabel) are placed level by level, parents centered over
+// their children, with subtrees packed as close as they can go without overlapping (Reingold–Tilford)
+func (n *Node) WriteSVG(w io.Writer, opts SVGOptions) error {
+	if opts.LevelGap <= 0 {
+		opts.LevelGap = 40
+	}
+	if opts.SiblingGap <= 0 {
+		opts.SiblingGap = 16
+	}
+	if opts.FontSize <= 0 {
+		opts.FontSize = 12
+	}
+	t := &treeLayout{opts: opts}
+	top := t.build(n, nil, 0, 0)
+	t.firstWalk(top)
+	starts := make([]float64, len(t.levels))
+	total := 0.0
+	for idx, size := range t.levels {
+		starts[idx] = total
+		total += size + opts.LevelGap
+	}
+	total -= opts.LevelGap
+	left := t.secondWalk(top, 0, starts)
+
+	// Across and along the levels to the picture, with a margin around it all
+	const margin = 10.0
+	right := 0.0
+	var all []*layoutNode
+	var collect func(v *layoutNode)
+	collect = func(v *layoutNode) {
+		all = append(all, v)
+		right = math.Max(right, v.x+v.breadth/2)
+		for _, kid := range v.kids {
+			collect(kid)
+		}
+	}
+	collect(top)
+	across := right - left
+	point := func(b, d float64) (float64, float64) {
+		b -= left
+		switch opts.Orientation {
+		case LeftRight:
+			return d + margin, b + margin
+		case BottomUp:
+			return b + margin, total - d + margin
+		case RightLeft:
+			return total - d + margin, b + margin
+		}
+		return b + margin, d + margin
+	}
+	width, height := across+2*margin, total+2*margin
+	if opts.Orientation == LeftRight || opts.Orientation == RightLeft {
+		width, height = height, width
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="sans-serif" font-size="%s">`+"\n",
+		svgNum(width), svgNum(height), svgNum(width), svgNum(height), svgNum(opts.FontSize))
+	bw.WriteString(`<g stroke="#888" fill="none">` + "\n")
+	for _, v := range all {
+		for _, kid := range v.kids {
+			x1, y1 := point(v.x, v.levelCenter+v.depth/2)
+			x2, y2 := point(kid.x, kid.levelCenter-kid.depth/2)
+			fmt.Fprintf(bw, `<line x1="%s" y1="%s" x2="%s" y2="%s"/>`+"\n", svgNum(x1), svgNum(y1), svgNum(x2), svgNum(y2))
+		}
+	}
+	bw.WriteString("</g>\n")
+	for _, v := range all {
+		cx, cy := point(v.x, v.levelCenter)
+		fmt.Fprintf(bw, `<g><rect x="%s" y="%s" width="%s" height="%s" rx="4" fill="#fff" stroke="#333"/>`,
+			svgNum(cx-v.w/2), svgNum(cy-v.h/2), svgNum(v.w), svgNum(v.h))
+		if len(v.lines) != 0 {
+			fmt.Fprintf(bw, `<text x="%s" y="%s" text-anchor="middle" dominant-baseline="middle">`,
+				svgNum(cx), svgNum(cy-float64(len(v.lines)-1)*opts.FontSize*0.6))
+			for idx, line := range v.lines {
+				dy := "0"
+				if idx != 0 {
+					dy = "1.2em"
+				}
+				fmt.Fprintf(bw, `<tspan x="%s" dy="%s">`, svgNum(cx), dy)
+				err := xml.EscapeText(bw, []byte(strings.ReplaceAll(line, "\n", " ")))
+				if err != nil {
+					return err
+				}
+				bw.WriteString("</tspan>")
+			}
+			bw.WriteString("</text>")
+		}
+		bw.WriteString("</g>\n")
+	}
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+// Secret util for writing a number in svg (to 2 decimal places at most)
+func svgNum(f float64) string {
+	f = math.Round(f*100) / 100
+	if f == 0 {
+		f = 0 // No "-0"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,166 @@
+package gonode_test
+
+import (
+	"encoding/xml"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+// The parts of the svg checked by the tests
+type svgDoc struct {
+	Width  float64 `xml:"width,attr"`
+	Height float64 `xml:"height,attr"`
+	Boxes  []struct {
+		Lines []struct {
+			X1 float64 `xml:"x1,attr"`
+			Y1 float64 `xml:"y1,attr"`
+		} `xml:"line"`
+		Rect struct {
+			X      float64 `xml:"x,attr"`
+			Y      float64 `xml:"y,attr"`
+			Width  float64 `xml:"width,attr"`
+			Height float64 `xml:"height,attr"`
+		} `xml:"rect"`
+		Text []string `xml:"text>tspan"`
+	} `xml:"g"`
+}
+
+func svgOf(t *testing.T, n *gonode.Node, opts gonode.SVGOptions) svgDoc {
+	buf := &strings.Builder{}
+	err := n.WriteSVG(buf, opts)
+	if err != nil {
+		t.Fatalf("WriteSVG %v", err)
+	}
+	doc := svgDoc{}
+	err = xml.Unmarshal([]byte(buf.String()), &doc)
+	if err != nil {
+		t.Fatalf("Expected valid xml, got %v\n%s", err, buf.String())
+	}
+	return doc
+}
+
+// Secret util for the number of lines, they're all in the first g
+func (doc svgDoc) lines() int {
+	return len(doc.Boxes[0].Lines)
+}
+
+// A tree where a naive layout leaves the small subtrees between the big ones bunched up on one side
+func svgTree() *gonode.Node {
+	n := gonode.NewNode()
+	big := n.NewChildWithTags("big")
+	for i := 0; i < 6; i += 1 {
+		big.NewChildWithDataAndTags(i, "leaf")
+	}
+	n.NewChildWithTags("a")
+	n.NewChildWithTags("b")
+	n.NewChildWithTags("c")
+	other := n.NewChildWithTags("big <2>")
+	for i := 0; i < 6; i += 1 {
+		other.NewChildWithData("leaf & more")
+	}
+	return n
+}
+
+func TestWriteSVG(t *testing.T) {
+	n := svgTree()
+	doc := svgOf(t, n, gonode.SVGOptions{Label: gonode.LabelBoth})
+	if doc.lines() != 17 {
+		t.Errorf("Expected 17 lines, got %d", doc.lines())
+	}
+	doc.Boxes = doc.Boxes[1:]
+	if len(doc.Boxes) != 18 {
+		t.Fatalf("Expected 18 boxes, got %d", len(doc.Boxes))
+	}
+	if doc.Boxes[0].Text[0] != "root" || doc.Boxes[11].Text[0] != "big <2>" || doc.Boxes[12].Text[0] != "leaf & more" {
+		t.Errorf("Expected labels from tags and data, got %v %v", doc.Boxes[0].Text, doc.Boxes[12].Text)
+	}
+	if doc.Boxes[2].Text[0] != "leaf" || doc.Boxes[2].Text[1] != "0" {
+		t.Errorf("Expected tags and data, got %v", doc.Boxes[2].Text)
+	}
+
+	// Boxes on the same level don't overlap, and everything fits
+	for i, a := range doc.Boxes {
+		r := a.Rect
+		if r.X < 0 || r.Y < 0 || r.X+r.Width > doc.Width || r.Y+r.Height > doc.Height {
+			t.Errorf("Expected box %d inside the picture", i)
+		}
+		for j, b := range doc.Boxes {
+			o := b.Rect
+			if i < j && r.X < o.X+o.Width && o.X < r.X+r.Width && r.Y < o.Y+o.Height && o.Y < r.Y+r.Height {
+				t.Errorf("Expected boxes %d and %d to not overlap", i, j)
+			}
+		}
+	}
+
+	// Parents centered over their children
+	center := func(idx int) float64 {
+		r := doc.Boxes[idx].Rect
+		return r.X + r.Width/2
+	}
+	if math.Abs(center(1)-(center(2)+center(7))/2) > 0.05 {
+		t.Errorf("Expected big centered over it's children")
+	}
+	if math.Abs(center(0)-(center(1)+center(11))/2) > 0.05 {
+		t.Errorf("Expected root centered over it's children")
+	}
+	// The small subtrees between the big ones are spread out evenly (rather than pushed up to the first)
+	gap1, gap2 := center(9)-center(8), center(10)-center(9)
+	if math.Abs(gap1-gap2) > 0.05 || gap1 < 50 {
+		t.Errorf("Expected the middle subtrees spread evenly, got %v %v", gap1, gap2)
+	}
+}
+
+func TestWriteSVGOrientation(t *testing.T) {
+	n := svgTree()
+	down := svgOf(t, n, gonode.SVGOptions{})
+	right := svgOf(t, n, gonode.SVGOptions{Orientation: gonode.LeftRight, LevelGap: 30})
+	up := svgOf(t, n, gonode.SVGOptions{Orientation: gonode.BottomUp})
+	for _, doc := range []*svgDoc{&down, &right, &up} {
+		doc.Boxes = doc.Boxes[1:]
+	}
+
+	if right.Boxes[0].Rect.X >= right.Boxes[1].Rect.X || right.Boxes[0].Rect.Y == right.Boxes[1].Rect.Y {
+		t.Errorf("Expected children to the right")
+	}
+	if up.Boxes[0].Rect.Y <= up.Boxes[1].Rect.Y || up.Width != down.Width || up.Height != down.Height {
+		t.Errorf("Expected children above, in a picture the same size")
+	}
+	if down.Boxes[0].Rect.Y >= down.Boxes[1].Rect.Y {
+		t.Errorf("Expected children below")
+	}
+
+	wide := svgOf(t, n, gonode.SVGOptions{SiblingGap: 50})
+	if wide.Width <= down.Width {
+		t.Errorf("Expected a larger gap to make a wider picture")
+	}
+	single := svgOf(t, gonode.NewNode(), gonode.SVGOptions{})
+	if len(single.Boxes) != 2 || single.lines() != 0 {
+		t.Errorf("Expected a single box")
+	}
+}
+
+func TestWriteSVGNoOverlap(t *testing.T) {
+	// Uneven tree with labels of all sizes
+	n := gonode.NewNode()
+	at := []*gonode.Node{n}
+	seed := 7
+	for i := 0; i < 300; i += 1 {
+		seed = (seed*1103515245 + 12345) % 2147483648
+		parent := at[seed%len(at)]
+		at = append(at, parent.NewChildWithData(strings.Repeat("x", seed%9)))
+	}
+	doc := svgOf(t, n, gonode.SVGOptions{Label: gonode.LabelData})
+	boxes := doc.Boxes[1:]
+	for i, a := range boxes {
+		r := a.Rect
+		for j := i + 1; j < len(boxes); j += 1 {
+			o := boxes[j].Rect
+			if r.X < o.X+o.Width && o.X < r.X+r.Width && r.Y < o.Y+o.Height && o.Y < r.Y+r.Height {
+				t.Fatalf("Expected boxes %d and %d to not overlap", i, j)
+			}
+		}
+	}
+}
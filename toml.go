@@ -0,0 +1,98 @@
+package gonode
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Makes a new "root" Node from a toml document, using the same layout as FromJSONValue
+//
+// Tables become children tagged by their key, arrays (and arrays of tables) become ordered children
+// and anything else (including dates and times) becomes data
+func FromTOML(data []byte) (*Node, error) {
+	doc := map[string]any{}
+	err := toml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSONValue(doc)
+}
+
+// Converts this Node (and it's children) into a toml document, using the same layout as ToJSONValue
+//
+// This Node must be an object (toml documents are tables) and nothing can be null (toml has no null)
+func (n *Node) ToTOML() ([]byte, error) {
+	v, err := n.ToJSONValue()
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("toml document must be an object, not %T", v)
+	}
+	err = checkTOML(doc, "")
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	err = toml.NewEncoder(buf).Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Secret util for finding null values (as toml would quietly drop them)
+//
+// Arrays made only of tables become []map[string]any, for toml to write them as arrays of tables
+func checkTOML(v any, path string) error {
+	switch val := v.(type) {
+	case nil:
+		return fmt.Errorf("%s: toml has no null", pathOrRoot(path))
+	case map[string]any:
+		for k, item := range val {
+			if arr, ok := item.([]any); ok {
+				if tables := tomlTables(arr); tables != nil {
+					val[k] = tables
+				}
+			}
+			err := checkTOML(val[k], path+"/"+k)
+			if err != nil {
+				return err
+			}
+		}
+	case []any:
+		for idx, item := range val {
+			err := checkTOML(item, fmt.Sprintf("%s/%d", path, idx))
+			if err != nil {
+				return err
+			}
+		}
+	case []map[string]any:
+		for idx, item := range val {
+			err := checkTOML(item, fmt.Sprintf("%s/%d", path, idx))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Secret util that returns the array as tables, or nil when not all items are tables
+func tomlTables(arr []any) []map[string]any {
+	if len(arr) == 0 {
+		return nil
+	}
+	tables := make([]map[string]any, 0, len(arr))
+	for _, item := range arr {
+		table, ok := item.(map[string]any)
+		if !ok {
+			return nil
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
@@ -0,0 +1,74 @@
+package gonode_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestTOMLRoundTrip(t *testing.T) {
+	doc := `title = "gonode"
+when = 2022-10-12T21:10:06Z
+
+[database]
+  enabled = true
+  ports = [8000, 8001]
+
+  [database.owner]
+    name = "bean"
+
+[[fruit]]
+  name = "apple"
+
+[[fruit]]
+  name = "banana"
+`
+	n, err := gonode.FromTOML([]byte(doc))
+	if err != nil {
+		t.Fatalf("FromTOML %v", err)
+	}
+	if n.ChildByTag("title").Data() != "gonode" {
+		t.Errorf("Expected 'title' as a child")
+	}
+	if _, ok := n.ChildByTag("when").Data().(time.Time); !ok {
+		t.Errorf("Expected datetime as time.Time, got %#v", n.ChildByTag("when").Data())
+	}
+	db := n.ChildByTag("database")
+	if db.ChildByTag("ports").Child(1).Data() != int64(8001) || db.ChildByTagDeep("name").Data() != "bean" {
+		t.Errorf("Expected nested table with it's values")
+	}
+	fruit := n.ChildByTag("fruit")
+	if fruit.Len() != 2 || fruit.Child(1).ChildByTag("name").Data() != "banana" {
+		t.Errorf("Expected array of tables as 2 ordered children")
+	}
+
+	back, err := n.ToTOML()
+	if err != nil {
+		t.Fatalf("ToTOML %v", err)
+	}
+	again, err := gonode.FromTOML(back)
+	if err != nil {
+		t.Fatalf("FromTOML (again) %v", err)
+	}
+	want, _ := n.ToJSONValue()
+	got, _ := again.ToJSONValue()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected the same document back, got\n%s", back)
+	}
+}
+
+func TestToTOMLErrors(t *testing.T) {
+	n := gonode.NewNode()
+	n.NewChildWithData(1)
+	n.NewChildWithData(2)
+	if _, err := n.ToTOML(); err == nil {
+		t.Errorf("Expected error, an array isn't a toml document")
+	}
+	n = gonode.NewNode()
+	n.NewChildWithTags("missing")
+	if _, err := n.ToTOML(); err == nil {
+		t.Errorf("Expected error, toml has no null")
+	}
+}
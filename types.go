@@ -0,0 +1,214 @@
+package gonode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Secret registry of data types known by name (for typed encoding)
+var registry = struct {
+	sync.RWMutex
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+}{
+	byName: map[string]reflect.Type{},
+	byType: map[reflect.Type]string{},
+}
+
+func init() {
+	builtin := map[string]any{
+		"bool":          false,
+		"string":        "",
+		"int":           int(0),
+		"int8":          int8(0),
+		"int16":         int16(0),
+		"int32":         int32(0),
+		"int64":         int64(0),
+		"uint":          uint(0),
+		"uint8":         uint8(0),
+		"uint16":        uint16(0),
+		"uint32":        uint32(0),
+		"uint64":        uint64(0),
+		"float32":       float32(0),
+		"float64":       float64(0),
+		"[]byte":        []byte{},
+		"time.Time":     time.Time{},
+		"time.Duration": time.Duration(0),
+		"*big.Int":      new(big.Int),
+	}
+	for name, sample := range builtin {
+		err := registerType(name, sample)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Registers the type of the given sample under the given name
+//
+// Registered types keep their Go type when Data goes through typed encoding (see MarshalTypedJSON),
+// they must survive a round trip through encoding/json. The type is registered with gob under the same name too
+// (see gob.RegisterName), so it can be used with gob without calling gob.Register.
+//
+// Builtin types (bool, string, numbers, *big.Int, []byte, time.Time, time.Duration and FileMeta) are already registered
+func RegisterType(name string, sample any) error {
+	err := checkType(name, sample)
+	if err != nil {
+		return err
+	}
+	err = gobRegisterName(name, sample)
+	if err != nil {
+		return err
+	}
+	return registerType(name, sample)
+}
+
+// Secret util for registering a type with the package only, gob isn't told about it
+func registerType(name string, sample any) error {
+	err := checkType(name, sample)
+	if err != nil {
+		return err
+	}
+	typ := reflect.TypeOf(sample)
+	registry.Lock()
+	defer registry.Unlock()
+	err = registryConflict(name, typ)
+	if err != nil {
+		return err
+	}
+	registry.byName[name] = typ
+	registry.byType[typ] = name
+	return nil
+}
+
+// Secret util for checking a type can be registered under the given name
+func checkType(name string, sample any) error {
+	if name == "" || sample == nil {
+		return fmt.Errorf("RegisterType needs a name and a non-nil sample")
+	}
+	typ := reflect.TypeOf(sample)
+	if typ == reflect.TypeOf(&Node{}) || typ == reflect.TypeOf(Node{}) {
+		return fmt.Errorf("data type of %s not allowed", typ)
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	return registryConflict(name, typ)
+}
+
+// Secret util for a name or type already registered for another, the registry must be locked
+func registryConflict(name string, typ reflect.Type) error {
+	if old, ok := registry.byName[name]; ok && old != typ {
+		return fmt.Errorf("type name %q already registered for %s", name, old)
+	}
+	if old, ok := registry.byType[typ]; ok && old != name {
+		return fmt.Errorf("type %s already registered as %q", typ, old)
+	}
+	return nil
+}
+
+// Secret util for obtaining the registered name of the data's type
+//
+// Returns "" for nil or unregistered types
+func typeName(data any) string {
+	if data == nil {
+		return ""
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.byType[reflect.TypeOf(data)]
+}
+
+// Secret util for decoding json data, as the registered type when given a type name
+//
+// Without a type name numbers are float64, except integers a float64 can't hold exactly
+// (those are int64, uint64 or *big.Int when wider, so nothing is lost)
+func decodeData(raw []byte, name string) (any, error) {
+	if name == "" {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var data any
+		err := dec.Decode(&data)
+		if err != nil {
+			return nil, err
+		}
+		return exactNumbers(data)
+	}
+	ptr, err := newData(name)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(raw, ptr.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// Secret util for turning the json.Number(s) of a plain json value (from decoding with UseNumber) into Go numbers
+func exactNumbers(v any) (any, error) {
+	switch val := v.(type) {
+	case json.Number:
+		return exactNumber(val)
+	case []any:
+		for idx, item := range val {
+			item, err := exactNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			val[idx] = item
+		}
+	case map[string]any:
+		for k, item := range val {
+			item, err := exactNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = item
+		}
+	}
+	return v, nil
+}
+
+// Secret util for a json number as float64, or int64 (uint64, *big.Int when wider) when it's an integer
+// a float64 can't hold exactly
+func exactNumber(num json.Number) (any, error) {
+	s := string(num)
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			if _, acc := new(big.Float).SetInt64(i).Float64(); acc != big.Exact {
+				return i, nil
+			}
+		} else if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			if _, acc := new(big.Float).SetUint64(u).Float64(); acc != big.Exact {
+				return u, nil
+			}
+		} else if b, ok := new(big.Int).SetString(s, 10); ok {
+			if _, acc := new(big.Float).SetInt(b).Float64(); acc != big.Exact {
+				return b, nil
+			}
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("number %s out of range", s)
+	}
+	return f, nil
+}
+
+// Secret util for making a pointer to a new (zero) value of the registered type
+func newData(name string) (reflect.Value, error) {
+	registry.RLock()
+	typ, ok := registry.byName[name]
+	registry.RUnlock()
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("unknown data type %q", name)
+	}
+	return reflect.New(typ), nil
+}
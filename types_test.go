@@ -0,0 +1,157 @@
+package gonode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/beanzilla/gonode"
+)
+
+type celsius struct {
+	Degrees float64
+	Where   string
+}
+
+func TestTypedJSON(t *testing.T) {
+	err := gonode.RegisterType("gonode_test.celsius", celsius{})
+	if err != nil {
+		t.Fatalf("RegisterType %v", err)
+	}
+	when := time.Date(2022, 10, 12, 21, 10, 6, 0, time.UTC)
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags(42, "int")
+	n.NewChildWithDataAndTags(int64(1<<62+1), "int64")
+	n.NewChildWithDataAndTags(uint8(7), "uint8")
+	n.NewChildWithDataAndTags(when, "time")
+	n.NewChildWithDataAndTags(time.Minute, "duration")
+	n.NewChildWithDataAndTags([]byte{0, 1, 2, 255}, "bytes")
+	n.NewChildWithDataAndTags(celsius{32, "freezing water"}, "custom")
+	n.NewChildWithDataAndTags(map[string]any{"plain": true}, "unregistered")
+
+	pay, err := n.MarshalTypedJSON()
+	if err != nil {
+		t.Fatalf("MarshalTypedJSON %v", err)
+	}
+	dummy := gonode.NewNode()
+	err = json.Unmarshal(pay, &dummy)
+	if err != nil {
+		t.Fatalf("json.Unmarshal %v", err)
+	}
+	for kid := range n.Iter() {
+		tag := kid.Tags()[0]
+		got := dummy.ChildByTag(tag)
+		if got == nil {
+			t.Errorf("Missing child '%s'", tag)
+			continue
+		}
+		if tag == "unregistered" {
+			if _, ok := got.Data().(map[string]any); !ok {
+				t.Errorf("Expected map[string]any for unregistered type, got %#v", got.Data())
+			}
+			continue
+		}
+		if tag == "bytes" {
+			if !bytes.Equal(got.Data().([]byte), kid.Data().([]byte)) {
+				t.Errorf("Expected %v, got %#v", kid.Data(), got.Data())
+			}
+			continue
+		}
+		if tag == "time" {
+			if !got.Data().(time.Time).Equal(when) {
+				t.Errorf("Expected %v, got %#v", when, got.Data())
+			}
+			continue
+		}
+		if got.Data() != kid.Data() {
+			t.Errorf("Expected %#v for '%s', got %#v", kid.Data(), tag, got.Data())
+		}
+	}
+
+	// Untyped json keeps the old behavior
+	pay, err = json.Marshal(n)
+	if err != nil {
+		t.Fatalf("json.Marshal %v", err)
+	}
+	dummy = gonode.NewNode()
+	err = json.Unmarshal(pay, &dummy)
+	if err != nil {
+		t.Fatalf("json.Unmarshal %v", err)
+	}
+	if _, ok := dummy.ChildByTag("int").Data().(float64); !ok {
+		t.Errorf("Expected float64 without typed encoding, got %#v", dummy.ChildByTag("int").Data())
+	}
+
+	// Unless a float64 can't hold the integer exactly
+	wide, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	tests := map[string]any{
+		`9007199254740992`:                `float64`,
+		`12345678901234567`:               int64(12345678901234567),
+		`18446744073709551615`:            uint64(18446744073709551615),
+		`-123456789012345678901234567890`: wide,
+		`1180591620717411303424`:          `float64`, // 1<<70
+	}
+	for in, want := range tests {
+		dummy = gonode.NewNode()
+		err = dummy.UnmarshalJSON([]byte(`{"Data":` + in + `}`))
+		if err != nil {
+			t.Fatalf("UnmarshalJSON %s %v", in, err)
+		}
+		got := dummy.Data()
+		if want == `float64` {
+			if _, ok := got.(float64); !ok {
+				t.Errorf("%s: expected float64, got %#v", in, got)
+			}
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %#v, got %#v", in, want, got)
+		}
+	}
+}
+
+func TestRegisterType(t *testing.T) {
+	if err := gonode.RegisterType("int", "not an int"); err == nil {
+		t.Errorf("Expected error, 'int' is already taken")
+	}
+	if err := gonode.RegisterType("my int", 0); err == nil {
+		t.Errorf("Expected error, int is already registered")
+	}
+	if err := gonode.RegisterType("node", gonode.Node{}); err == nil {
+		t.Errorf("Expected error, Node isn't allowed as data")
+	}
+	dummy := gonode.NewNode()
+	err := json.Unmarshal([]byte(`{"Data": 1, "Type": "no such type"}`), &dummy)
+	if err == nil {
+		t.Errorf("Expected error for unknown type")
+	}
+}
+
+func TestBigIntData(t *testing.T) {
+	wide, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	n := gonode.NewNodeWithData(wide)
+	codecs := map[string]struct {
+		marshal   func() ([]byte, error)
+		unmarshal func(n *gonode.Node, data []byte) error
+	}{
+		"typed json": {n.MarshalTypedJSON, (*gonode.Node).UnmarshalJSON},
+		"gob":        {n.GobEncode, (*gonode.Node).GobDecode},
+		"cbor":       {n.MarshalCBOR, (*gonode.Node).UnmarshalCBOR},
+		"binary":     {n.MarshalBinary, (*gonode.Node).UnmarshalBinary},
+	}
+	for name, c := range codecs {
+		pay, err := c.marshal()
+		if err != nil {
+			t.Fatalf("%s marshal %v", name, err)
+		}
+		dummy := gonode.NewNode()
+		err = c.unmarshal(dummy, pay)
+		if err != nil {
+			t.Fatalf("%s unmarshal %v", name, err)
+		}
+		if got, ok := dummy.Data().(*big.Int); !ok || got.Cmp(wide) != 0 {
+			t.Errorf("%s: expected %v as *big.Int, got %#v", name, wide, dummy.Data())
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package gonode
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Tags used for the parts of xml that aren't elements
+const (
+	XMLTextTag    = "#text"    // Text mixed in with elements, the text is the data
+	XMLCommentTag = "#comment" // A comment, the comment is the data
+)
+
+// The attribute holding the namespace (url) of an element
+const XMLNamespaceAttr = "xmlns"
+
+// Makes a new "root" Node from a xml document
+//
+// Elements become children (tagged by their name) with their attributes as attributes (namespaced ones as "{space}name"),
+// text becomes data, unless mixed with elements where it becomes children tagged XMLTextTag
+func FromXML(r io.Reader) (*Node, error) {
+	d := xml.NewDecoder(r)
+	n := NewNode()
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			kid, err := readXMLElement(d, t)
+			if err != nil {
+				return nil, err
+			}
+			n.AddChild(kid)
+		case xml.Comment:
+			n.NewChildWithDataAndTags(string(t), XMLCommentTag)
+		}
+	}
+}
+
+// Secret util for reading an element (after it's start) up to and including it's end
+func readXMLElement(d *xml.Decoder, start xml.StartElement) (*Node, error) {
+	o := &Node{
+		tags: []string{start.Name.Local},
+	}
+	if start.Name.Space != "" {
+		o.SetAttr(XMLNamespaceAttr, start.Name.Space)
+	}
+	for _, a := range start.Attr {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+			continue // Namespace declarations are made again when written
+		}
+		o.SetAttr(xmlAttrKey(a.Name), a.Value)
+	}
+	text := &strings.Builder{}
+	flush := func() {
+		if text.Len() != 0 {
+			o.NewChildWithDataAndTags(text.String(), XMLTextTag)
+			text.Reset()
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			flush()
+			kid, err := readXMLElement(d, t)
+			if err != nil {
+				return nil, err
+			}
+			o.AddChild(kid)
+		case xml.CharData:
+			text.Write(t)
+		case xml.Comment:
+			flush()
+			o.NewChildWithDataAndTags(string(t), XMLCommentTag)
+		case xml.EndElement:
+			flush()
+			o.tidyXMLText()
+			return o, nil
+		}
+	}
+}
+
+// Secret util that turns lone text into data, and drops whitespace (indentation) between elements
+//
+// Whitespace is only dropped when it holds a line break and the element has no other text,
+// so mixed content like "<b>a</b> <i>b</i>" keeps it's spaces
+func (n *Node) tidyXMLText() {
+	if n.Len() == 1 && n.children[0].HasTag(XMLTextTag) {
+		n.data = n.children[0].data
+		n.RmAllChildren()
+		return
+	}
+	blank := []int{}
+	for idx, kid := range n.children {
+		s, ok := kid.data.(string)
+		if !ok || !kid.HasTag(XMLTextTag) {
+			continue
+		}
+		if strings.TrimSpace(s) != "" {
+			return // Mixed content, keep all of it
+		}
+		if strings.Contains(s, "\n") {
+			blank = append(blank, idx)
+		}
+	}
+	n.RmChild(blank...)
+}
+
+// Secret util for the attribute key of a xml attribute
+func xmlAttrKey(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
+
+// Secret util for the xml attribute of an attribute key
+func xmlAttrName(key string) xml.Name {
+	if strings.HasPrefix(key, "{") {
+		if end := strings.Index(key, "}"); end != -1 {
+			return xml.Name{Space: key[1:end], Local: key[end+1:]}
+		}
+	}
+	return xml.Name{Local: key}
+}
+
+// Writes the children of this Node as a xml document (the reverse of FromXML)
+func (n *Node) WriteXML(w io.Writer) error {
+	e := xml.NewEncoder(w)
+	for _, kid := range n.children {
+		err := kid.writeXML(e, xml.Name{}, "")
+		if err != nil {
+			return err
+		}
+	}
+	return e.Flush()
+}
+
+// Custom Marshaler for xml, this Node is the element (named by it's first tag)
+func (n *Node) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return n.writeXML(e, start.Name, "")
+}
+
+// Custom Unmarshaler for xml, this Node becomes the element (replacing what was there)
+func (n *Node) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	o, err := readXMLElement(d, start)
+	if err != nil {
+		return err
+	}
+	n.Merge(o, ModeReplace)
+	return nil
+}
+
+// Secret util for writing this Node as xml, named by the first tag (or the given name when there are no tags)
+//
+// The namespace is left out when it's the same as the parent's (it's inherited)
+func (n *Node) writeXML(e *xml.Encoder, name xml.Name, parentSpace string) error {
+	switch {
+	case n.HasTag(XMLTextTag):
+		return e.EncodeToken(xml.CharData(xmlText(n.data)))
+	case n.HasTag(XMLCommentTag):
+		return e.EncodeToken(xml.Comment(xmlText(n.data)))
+	}
+	if len(n.tags) != 0 {
+		name = xml.Name{Local: n.tags[0]}
+	}
+	if name.Local == "" {
+		return fmt.Errorf("xml element needs a tag as it's name")
+	}
+	space := n.attrs[XMLNamespaceAttr]
+	if space != parentSpace {
+		name.Space = space
+	}
+	start := xml.StartElement{Name: name}
+	keys := make([]string, 0, len(n.attrs))
+	for k := range n.attrs {
+		if k != XMLNamespaceAttr {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		start.Attr = append(start.Attr, xml.Attr{Name: xmlAttrName(k), Value: n.attrs[k]})
+	}
+	err := e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+	if n.data != nil {
+		err = e.EncodeToken(xml.CharData(xmlText(n.data)))
+		if err != nil {
+			return err
+		}
+	}
+	for _, kid := range n.children {
+		err = kid.writeXML(e, xml.Name{}, space)
+		if err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// Secret util for the text of data
+func xmlText(data any) string {
+	switch d := data.(type) {
+	case nil:
+		return ""
+	case string:
+		return d
+	case []byte:
+		return string(d)
+	}
+	return fmt.Sprint(data)
+}
@@ -0,0 +1,105 @@
+package gonode_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+)
+
+func TestXMLRoundTrip(t *testing.T) {
+	doc := `<feed xmlns="http://www.w3.org/2005/Atom"><title type="text">Example</title>` +
+		`<entry id="1"><summary>Some <b>bold</b> text</summary></entry><!-- the end --></feed>`
+	n, err := gonode.FromXML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("FromXML %v", err)
+	}
+	feed := n.ChildByTag("feed")
+	if feed == nil || !feed.HasAttr(gonode.XMLNamespaceAttr, "http://www.w3.org/2005/Atom") {
+		t.Fatalf("Expected 'feed' with it's namespace")
+	}
+	title := feed.ChildByTag("title")
+	if title.Data() != "Example" || !title.HasAttr("type", "text") {
+		t.Errorf("Expected title text as data and 'type' attribute")
+	}
+	summary := feed.ChildByTagDeep("summary")
+	if summary.Len() != 3 || summary.Child(0).Data() != "Some " || summary.Child(1).Data() != "bold" {
+		t.Errorf("Expected mixed content as 3 children")
+	}
+	if feed.ChildByTag(gonode.XMLCommentTag) == nil {
+		t.Errorf("Expected the comment kept")
+	}
+
+	buf := &bytes.Buffer{}
+	err = n.WriteXML(buf)
+	if err != nil {
+		t.Fatalf("WriteXML %v", err)
+	}
+	want := `<feed xmlns="http://www.w3.org/2005/Atom"><title type="text">Example</title>` +
+		`<entry id="1"><summary>Some <b>bold</b> text</summary></entry><!-- the end --></feed>`
+	if buf.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, buf.String())
+	}
+	again, err := gonode.FromXML(buf)
+	if err != nil || !again.ChildByTagDeep("b").HasAttr(gonode.XMLNamespaceAttr, "http://www.w3.org/2005/Atom") {
+		t.Errorf("Expected written xml to be read back (%v)", err)
+	}
+}
+
+func TestXMLIndentedInput(t *testing.T) {
+	doc := "<list>\n  <item>a</item>\n  <item>b</item>\n</list>\n"
+	n, err := gonode.FromXML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("FromXML %v", err)
+	}
+	list := n.Child(0)
+	if list.Len() != 2 || list.Child(1).Data() != "b" {
+		t.Errorf("Expected indentation dropped, got %d children", list.Len())
+	}
+}
+
+func TestXMLMixedSpaces(t *testing.T) {
+	n, err := gonode.FromXML(strings.NewReader("<doc>\n  <p><b>a</b> <i>b</i></p>\n  <p>x <b>y</b>\n</p>\n</doc>"))
+	if err != nil {
+		t.Fatalf("FromXML %v", err)
+	}
+	doc := n.Child(0)
+	if doc.Len() != 2 {
+		t.Fatalf("Expected indentation dropped, got %d children", doc.Len())
+	}
+	if p := doc.Child(0); p.Len() != 3 || p.Child(1).Data() != " " {
+		t.Errorf("Expected the space between elements kept")
+	}
+	if p := doc.Child(1); p.Len() != 3 || p.Child(2).Data() != "\n" {
+		t.Errorf("Expected whitespace kept in mixed content")
+	}
+	buf := &bytes.Buffer{}
+	err = doc.Child(0).WriteXML(buf)
+	if err != nil || buf.String() != "<b>a</b> <i>b</i>" {
+		t.Errorf("Expected the space written back, got %s (%v)", buf.String(), err)
+	}
+}
+
+func TestXMLMarshaler(t *testing.T) {
+	n := gonode.NewNode()
+	n.RmTag("root")
+	n.AddTag("config")
+	n.NewChildWithDataAndTags(42, "answer").SetAttr("unit", "none")
+	pay, err := xml.Marshal(n)
+	if err != nil {
+		t.Fatalf("xml.Marshal %v", err)
+	}
+	if string(pay) != `<config><answer unit="none">42</answer></config>` {
+		t.Errorf("Unexpected xml %s", pay)
+	}
+	dummy := gonode.NewNode()
+	err = xml.Unmarshal(pay, dummy)
+	if err != nil {
+		t.Fatalf("xml.Unmarshal %v", err)
+	}
+	if !dummy.HasTag("config") || dummy.Child(0).Data() != "42" || dummy.Child(0).Parent() != dummy {
+		t.Errorf("Expected element back in the Node")
+	}
+}
@@ -0,0 +1,352 @@
+package gonode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The attributes used to keep yaml comments (from FromYAML) so ToYAML can write them back
+const (
+	YAMLHeadCommentAttr = "yaml:head"
+	YAMLLineCommentAttr = "yaml:line"
+	YAMLFootCommentAttr = "yaml:foot"
+)
+
+// Secret util for making a DecodeError pointing at a yaml node
+func yamlFail(path string, v *yaml.Node, err error) error {
+	return &DecodeError{Path: path, Err: fmt.Errorf("line %d: %w", v.Line, err)}
+}
+
+// Secret util for making a yaml string scalar
+func yamlString(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// Custom Marshaler for yaml (using the same layout as MarshalJSON)
+func (n *Node) MarshalYAML() (any, error) {
+	return n.yamlEnvelope()
+}
+
+// Secret util for making the yaml version of the envelope
+func (n *Node) yamlEnvelope() (*yaml.Node, error) {
+	m := &yaml.Node{Kind: yaml.MappingNode}
+	if len(n.tags) != 0 {
+		tgs := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, tag := range n.tags {
+			tgs.Content = append(tgs.Content, yamlString(tag))
+		}
+		m.Content = append(m.Content, yamlString("Tags"), tgs)
+	}
+	if len(n.attrs) != 0 {
+		keys := make([]string, 0, len(n.attrs))
+		for k := range n.attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		atrs := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range keys {
+			atrs.Content = append(atrs.Content, yamlString(k), yamlString(n.attrs[k]))
+		}
+		m.Content = append(m.Content, yamlString("Attrs"), atrs)
+	}
+	if n.data != nil {
+		data := &yaml.Node{}
+		err := data.Encode(n.data)
+		if err != nil {
+			return nil, err
+		}
+		m.Content = append(m.Content, yamlString("Data"), data)
+	}
+	if n.Len() != 0 {
+		kids := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, kid := range n.children {
+			k, err := kid.yamlEnvelope()
+			if err != nil {
+				return nil, err
+			}
+			kids.Content = append(kids.Content, k)
+		}
+		m.Content = append(m.Content, yamlString("Children"), kids)
+	}
+	return m, nil
+}
+
+// Custom Unmarshaler for yaml (using the same layout as MarshalJSON)
+//
+// Malformed input returns a *DecodeError naming where the problem is, like UnmarshalJSON
+func (n *Node) UnmarshalYAML(value *yaml.Node) error {
+	o := &Node{}
+	err := o.fromYAMLEnvelope(value, "")
+	if err != nil {
+		return err
+	}
+	n.Merge(o, ModeReplace)
+	return nil
+}
+
+// Secret util for filling this Node from the yaml version of the envelope
+func (n *Node) fromYAMLEnvelope(v *yaml.Node, path string) error {
+	v = yamlValue(v)
+	if v.Kind != yaml.MappingNode {
+		return yamlFail(path, v, errExpectedObject)
+	}
+	var data *yaml.Node
+	name := ""
+	for i := 0; i+1 < len(v.Content); i += 2 {
+		key, val := v.Content[i].Value, yamlValue(v.Content[i+1])
+		at := joinPath(path, key)
+		switch key {
+		case "Data":
+			data = val
+		case "Type":
+			if val.Kind != yaml.ScalarNode {
+				return yamlFail(at, val, errExpectedString)
+			}
+			name = val.Value
+		case "Tags":
+			if val.Kind != yaml.SequenceNode {
+				return yamlFail(at, val, errExpectedArray)
+			}
+			for idx, t := range val.Content {
+				t = yamlValue(t)
+				if t.Kind != yaml.ScalarNode {
+					return yamlFail(at+"["+strconv.Itoa(idx)+"]", t, errExpectedString)
+				}
+				n.AddTag(t.Value)
+			}
+		case "Attrs":
+			if val.Kind != yaml.MappingNode {
+				return yamlFail(at, val, errExpectedObject)
+			}
+			for j := 0; j+1 < len(val.Content); j += 2 {
+				k, a := val.Content[j].Value, yamlValue(val.Content[j+1])
+				if a.Kind != yaml.ScalarNode {
+					return yamlFail(joinPath(at, k), a, errExpectedString)
+				}
+				n.SetAttr(k, a.Value)
+			}
+		case "Children":
+			if val.Kind != yaml.SequenceNode {
+				return yamlFail(at, val, errExpectedArray)
+			}
+			for idx, kid := range val.Content {
+				err := n.NewChild().fromYAMLEnvelope(kid, at+"["+strconv.Itoa(idx)+"]")
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if data != nil {
+		d, err := yamlData(data, name)
+		if err != nil {
+			return yamlFail(joinPath(path, "Data"), data, err)
+		}
+		err = n.SetData(d)
+		if err != nil {
+			return yamlFail(joinPath(path, "Data"), data, err)
+		}
+	}
+	return nil
+}
+
+// Secret util that follows aliases and documents to the actual value
+func yamlValue(v *yaml.Node) *yaml.Node {
+	for {
+		switch {
+		case v.Kind == yaml.AliasNode && v.Alias != nil:
+			v = v.Alias
+		case v.Kind == yaml.DocumentNode && len(v.Content) != 0:
+			v = v.Content[0]
+		default:
+			return v
+		}
+	}
+}
+
+// Secret util for decoding yaml data, as the registered type when given a type name
+func yamlData(v *yaml.Node, name string) (any, error) {
+	if name == "" {
+		var data any
+		err := v.Decode(&data)
+		return data, err
+	}
+	ptr, err := newData(name)
+	if err != nil {
+		return nil, err
+	}
+	err = v.Decode(ptr.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// Makes a new "root" Node from a yaml document, using the same layout as FromJSONValue
+//
+// Mappings become children tagged by their key (kept in order), sequences become ordered children
+// and scalars become data. Comments are kept as attributes (see YAMLHeadCommentAttr) for ToYAML to write back
+func FromYAML(data []byte) (*Node, error) {
+	doc := &yaml.Node{}
+	err := yaml.Unmarshal(data, doc)
+	if err != nil {
+		return nil, err
+	}
+	n := NewNode()
+	if doc.Kind == 0 {
+		return n, nil // Empty document
+	}
+	n.yamlComments(nil, doc)
+	err = n.fromYAMLValue(nil, doc.Content[0], "")
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Secret util for keeping the comments of a value (and it's key) as attributes
+func (n *Node) yamlComments(key, v *yaml.Node) {
+	pick := func(get func(*yaml.Node) string) string {
+		if key != nil && get(key) != "" {
+			return get(key)
+		}
+		return get(v)
+	}
+	comments := map[string]string{
+		YAMLHeadCommentAttr: pick(func(y *yaml.Node) string { return y.HeadComment }),
+		YAMLLineCommentAttr: pick(func(y *yaml.Node) string { return y.LineComment }),
+		YAMLFootCommentAttr: pick(func(y *yaml.Node) string { return y.FootComment }),
+	}
+	for attr, comment := range comments {
+		if comment != "" {
+			n.SetAttr(attr, comment)
+		}
+	}
+}
+
+// Secret util for filling this Node from a yaml value
+func (n *Node) fromYAMLValue(key, v *yaml.Node, path string) error {
+	n.yamlComments(key, v)
+	v = yamlValue(v)
+	switch v.Kind {
+	case yaml.MappingNode:
+		n.SetAttr(KindAttr, kindObject)
+		for i := 0; i+1 < len(v.Content); i += 2 {
+			k := v.Content[i]
+			err := n.NewChildWithTags(k.Value).fromYAMLValue(k, v.Content[i+1], path+"/"+k.Value)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		n.SetAttr(KindAttr, kindArray)
+		for idx, item := range v.Content {
+			err := n.NewChild().fromYAMLValue(nil, item, path+"/"+strconv.Itoa(idx))
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if v.Tag == "!!null" {
+			return nil
+		}
+		data, err := yamlData(v, "")
+		if err != nil {
+			return yamlFail(pathOrRoot(path), v, err)
+		}
+		err = n.SetData(data)
+		if err != nil {
+			return yamlFail(pathOrRoot(path), v, err)
+		}
+	default:
+		return yamlFail(pathOrRoot(path), v, errors.New("unsupported yaml value"))
+	}
+	return nil
+}
+
+// Converts this Node (and it's children) into a yaml document, using the same layout as ToJSONValue
+//
+// Unlike ToJSONValue the order of children is kept, as are comments from FromYAML
+func (n *Node) ToYAML() ([]byte, error) {
+	v, err := n.toYAMLValue("")
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{v}}
+	doc.HeadComment = n.attrs[YAMLHeadCommentAttr]
+	doc.FootComment = n.attrs[YAMLFootCommentAttr]
+	v.LineComment = n.attrs[YAMLLineCommentAttr]
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	err = enc.Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+	err = enc.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Secret util for converting this Node into a yaml value
+func (n *Node) toYAMLValue(path string) (*yaml.Node, error) {
+	kind, err := n.kind(path)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case kindObject:
+		m := &yaml.Node{Kind: yaml.MappingNode}
+		seen := map[string]bool{}
+		for idx, kid := range n.children {
+			if len(kid.tags) == 0 {
+				return nil, fmt.Errorf("%s/%d: no tag to use as key", path, idx)
+			}
+			key := kid.tags[0]
+			if seen[key] {
+				return nil, fmt.Errorf("%s/%s: duplicate key", path, key)
+			}
+			seen[key] = true
+			v, err := kid.toYAMLValue(path + "/" + key)
+			if err != nil {
+				return nil, err
+			}
+			k := yamlString(key)
+			k.HeadComment = kid.attrs[YAMLHeadCommentAttr]
+			k.FootComment = kid.attrs[YAMLFootCommentAttr]
+			if v.Kind == yaml.ScalarNode {
+				v.LineComment = kid.attrs[YAMLLineCommentAttr]
+			} else {
+				k.LineComment = kid.attrs[YAMLLineCommentAttr]
+			}
+			m.Content = append(m.Content, k, v)
+		}
+		return m, nil
+	case kindArray:
+		s := &yaml.Node{Kind: yaml.SequenceNode}
+		for idx, kid := range n.children {
+			v, err := kid.toYAMLValue(path + "/" + strconv.Itoa(idx))
+			if err != nil {
+				return nil, err
+			}
+			v.HeadComment = kid.attrs[YAMLHeadCommentAttr]
+			v.LineComment = kid.attrs[YAMLLineCommentAttr]
+			v.FootComment = kid.attrs[YAMLFootCommentAttr]
+			s.Content = append(s.Content, v)
+		}
+		return s, nil
+	}
+	v := &yaml.Node{}
+	err = v.Encode(n.data)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,84 @@
+package gonode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/beanzilla/gonode"
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLEnvelope(t *testing.T) {
+	n := gonode.NewNode()
+	n.NewChildWithDataAndTags(9.81, "gravity")
+	lvl := n.NewChildWithTags("level 2")
+	lvl.NewChildWithDataAndTags(32, "freezing water").SetAttr("unit", "celsius")
+	lvl.NewChildWithDataAndTags([]any{"a", "b"}, "list")
+
+	pay, err := yaml.Marshal(n)
+	if err != nil {
+		t.Fatalf("yaml.Marshal %v", err)
+	}
+	dummy := gonode.NewNode()
+	err = yaml.Unmarshal(pay, dummy)
+	if err != nil {
+		t.Fatalf("yaml.Unmarshal %v", err)
+	}
+	kid := dummy.ChildByTagDeep("freezing water")
+	if kid == nil || kid.Data() != 32 || !kid.HasAttr("unit", "celsius") {
+		t.Errorf("Expected 'freezing water' back with int data, got:\n%s", pay)
+	}
+	if kid.Parent().Parent() != dummy {
+		t.Errorf("Expected parents to be correct")
+	}
+	if !strings.HasPrefix(string(pay), "Tags:\n    - root\n") {
+		t.Errorf("Expected Tags first, got:\n%s", pay)
+	}
+
+	err = yaml.Unmarshal([]byte("Children:\n  - Tags: [a, [b]]\n"), dummy)
+	if err == nil || err.Error() != "Children[0].Tags[1]: line 2: expected string" {
+		t.Errorf("Expected positioned error, got %v", err)
+	}
+}
+
+func TestYAMLNatural(t *testing.T) {
+	doc := `# Service settings
+name: gonode # the name
+ports:
+  - 80
+  - 443
+# Nested settings
+db:
+  host: localhost
+  timeout: 2.5
+  empty: null
+`
+	n, err := gonode.FromYAML([]byte(doc))
+	if err != nil {
+		t.Fatalf("FromYAML %v", err)
+	}
+	if n.Child(0).Data() != "gonode" || n.Child(1).Child(1).Data() != 443 {
+		t.Errorf("Expected children in document order")
+	}
+	if c, _ := n.ChildByTag("db").Attr(gonode.YAMLHeadCommentAttr); c != "# Nested settings" {
+		t.Errorf("Expected head comment on 'db', got '%s'", c)
+	}
+	if n.ChildByTagDeep("empty").Data() != nil {
+		t.Errorf("Expected null as nil data")
+	}
+	back, err := n.ToYAML()
+	if err != nil {
+		t.Fatalf("ToYAML %v", err)
+	}
+	if string(back) != doc {
+		t.Errorf("Expected round trip, got:\n%s", back)
+	}
+
+	// Trees not made from yaml work too
+	o := gonode.NewNode()
+	o.NewChildWithDataAndTags("celsius", "unit")
+	back, err = o.ToYAML()
+	if err != nil || string(back) != "unit: celsius\n" {
+		t.Errorf("Expected 'unit: celsius', got %q (%v)", back, err)
+	}
+}